@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/charmbracelet/log"
+)
+
+// how long uploading the slips to the bucket can take
+const uploadTimeout = 2 * time.Minute
+
+// bucketNamePattern matches bucket names: 3 to 63 lowercase letters, digits, dots and dashes,
+// or underscores, which only Cloud Storage allows
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,61}[a-z0-9]$`)
+
+// bucketTarget is an --outfile in object storage, like s3://bucket/slips/packingslip.pdf.
+// The slips are written to a temporary directory, then uploaded under the key's directory.
+type bucketTarget struct {
+	scheme string // s3 or gs
+	bucket string
+	dir    string
+	tmpDir string
+}
+
+// useBucket points the --outfile at a temporary directory when it's an s3:// or gs:// URL,
+// so the slips are written there and uploaded after
+func (r *RenderCmd) useBucket() error {
+	scheme, rest, ok := strings.Cut(r.OutFilename, "://")
+	if !ok || (scheme != "s3" && scheme != "gs") {
+		return nil
+	}
+	if r.Preview || r.Watch || r.Zip != "" {
+		return fmt.Errorf("an %s:// --outfile can't be used with --preview, --watch or --zip", scheme)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if !bucketNamePattern.MatchString(bucket) {
+		return fmt.Errorf("--outfile %s: %q isn't a bucket name (3 to 63 lowercase letters, digits, dots, dashes or underscores)", r.OutFilename, bucket)
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return fmt.Errorf("--outfile %s: the key needs a file name, like %s://%s/packingslip.pdf", r.OutFilename, scheme, bucket)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "packingslipper-")
+	if err != nil {
+		return err
+	}
+	r.bucket = &bucketTarget{scheme: scheme, bucket: bucket, dir: path.Dir(key), tmpDir: tmpDir}
+	r.OutFilename = filepath.Join(tmpDir, path.Base(key))
+	return nil
+}
+
+// url returns the URL the file goes to in the bucket
+func (b *bucketTarget) url(fn string) string {
+	return fmt.Sprintf("%s://%s/%s", b.scheme, b.bucket, b.key(fn))
+}
+
+// key returns the object key the file is uploaded to: its name under the --outfile key's directory
+func (b *bucketTarget) key(fn string) string {
+	return path.Join(b.dir, filepath.Base(fn))
+}
+
+// cleanup removes the temporary directory the slips were written to
+func (b *bucketTarget) cleanup() {
+	if b != nil {
+		os.RemoveAll(b.tmpDir)
+	}
+}
+
+// uploadSlips uploads the files writeSlips wrote to the bucket, with the credentials from the
+// environment the way the AWS and Google Cloud tools find them
+func (r *RenderCmd) uploadSlips() error {
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	upload := uploadS3
+	if r.bucket.scheme == "gs" {
+		upload = uploadGCS
+	}
+	for _, fn := range r.outFilenames() {
+		if err := upload(ctx, r.bucket, fn); err != nil {
+			return fmt.Errorf("failed to upload the slip to %s: %w", r.bucket.url(fn), err)
+		}
+		log.Info("Uploaded slip", "url", r.bucket.url(fn))
+	}
+	return nil
+}
+
+// uploadS3 uploads the file to the S3 bucket
+func uploadS3(ctx context.Context, b *bucketTarget, fn string) error {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = s3.NewFromConfig(cfg).PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.bucket),
+		Key:         aws.String(b.key(fn)),
+		Body:        f,
+		ContentType: aws.String(contentType(fn)),
+	})
+	return err
+}
+
+// uploadGCS uploads the file to the Cloud Storage bucket
+func uploadGCS(ctx context.Context, b *bucketTarget, fn string) error {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := client.Bucket(b.bucket).Object(b.key(fn)).NewWriter(ctx)
+	w.ContentType = contentType(fn)
+	if _, err := io.Copy(w, f); err != nil {
+		w.Close()
+		return err
+	}
+	// the upload only finishes, or fails, when the writer is closed
+	return w.Close()
+}
+
+// contentType returns the MIME type the file is stored with, from its extension
+func contentType(fn string) string {
+	if t := mime.TypeByExtension(filepath.Ext(fn)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/rahji/packingslipper/slip"
+	"gopkg.in/yaml.v2"
+)
+
+type ConfigCmd struct {
+	Show ConfigShowCmd `kong:"cmd,help='Print the configuration in effect as YAML: the config files merged, the render flags applied and the defaults filled in'"`
+}
+
+// ConfigShowCmd takes the same flags as render, so it shows the config a render with them would use
+type ConfigShowCmd struct {
+	RenderCmd `kong:"embed"`
+}
+
+// redacted is what's shown in place of the API token
+const redacted = "<redacted>"
+
+// Run prints the effective configuration, with the shop from the secrets but not the token.
+// A config that doesn't pass validation, or secrets that can't be decrypted, are warned about
+// rather than stopping it.
+func (c *ConfigShowCmd) Run(cli *CLIFlags) error {
+	config, err := loadConfigFiles(cli.ConfigFilenames)
+	if err != nil {
+		return err
+	}
+	// the config is still worth seeing without the secrets
+	secrets, err := loadSecrets(cli.SecretsFilename)
+	if err != nil {
+		log.Warn("Showing the config without the secrets", "err", err)
+		secrets = &Secrets{}
+	}
+
+	if err := c.applyPreset(config); err != nil {
+		return err
+	}
+	c.applyFlags(config)
+	if err := config.Validate(); err != nil {
+		log.Warn("The config isn't valid", "err", err)
+	}
+
+	effective := struct {
+		slip.Config `yaml:",inline"`
+		API         struct {
+			Shop  string `yaml:"shop"`
+			Token string `yaml:"token"`
+		} `yaml:"api"`
+	}{Config: config.WithDefaults()}
+	effective.API.Shop = secrets.API.ShopName
+	if secrets.API.Token != "" {
+		effective.API.Token = redacted
+	}
+
+	out, err := yaml.Marshal(effective)
+	if err != nil {
+		return fmt.Errorf("failed to write the config as YAML: %w", err)
+	}
+	for _, fn := range cli.ConfigFilenames {
+		fmt.Printf("# %s\n", fn)
+	}
+	fmt.Printf("# %s\n", cli.SecretsFilename)
+	_, err = os.Stdout.Write(out)
+	return err
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// splitDestinations looks for orders whose fulfillment orders go to more than one address, which apps
+// that ship one order to several places create, since an order only has one shipping address.
+// With --per-destination split, each destination becomes a slip of its own, with its items and address,
+// named like #1001-2. With warn, the order keeps its shipping address and the other destinations
+// are warned about. With off, the fulfillment orders aren't fetched at all.
+func (r *RenderCmd) splitDestinations(ctx context.Context, client *goshopify.Client, orders []goshopify.Order) ([]goshopify.Order, error) {
+	if r.PerDestination == "off" || r.Draft || r.FulfillmentOrderID != 0 {
+		return orders, nil
+	}
+
+	var split []goshopify.Order
+	for _, order := range orders {
+		fulfillmentOrders, err := client.FulfillmentOrder.List(ctx, order.Id, nil)
+		if missingScope(err) {
+			warn("Can't read the fulfillment orders, the API token may be missing scopes, not checking for more than one destination",
+				"scopes", "read_merchant_managed_fulfillment_orders", "err", err)
+			return orders, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fulfillment orders for %s: %w", order.Name, err)
+		}
+
+		destinations := groupDestinations(fulfillmentOrders)
+		if len(destinations) <= 1 {
+			split = append(split, order)
+			continue
+		}
+
+		if r.PerDestination != "split" {
+			var others []string
+			for _, d := range destinations {
+				if !sameDestination(d.address, order.ShippingAddress) {
+					others = append(others, oneLineAddress(d.address))
+				}
+			}
+			warn("Order ships to more than one address, only its shipping address is on the slip (use --per-destination split)",
+				"order", order.Name, "others", strings.Join(others, "; "))
+			split = append(split, order)
+			continue
+		}
+		for i, d := range destinations {
+			split = append(split, destinationOrder(order, d, i+1))
+		}
+	}
+	return split, nil
+}
+
+// destination is an address that some of an order's items ship to, and how many of each of them by line item ID
+type destination struct {
+	address    *goshopify.Address
+	quantities map[uint64]int
+}
+
+// groupDestinations groups the items of the fulfillment orders by where they're going,
+// in the order each address first appears. Fulfillment orders without an address, like
+// digital or pickup ones, are left out.
+func groupDestinations(fulfillmentOrders []goshopify.FulfillmentOrder) []destination {
+	var destinations []destination
+	for _, fo := range fulfillmentOrders {
+		d := fo.Destination
+		if d.Address1 == "" && d.City == "" && d.Zip == "" {
+			continue
+		}
+		address := &goshopify.Address{
+			FirstName:    d.FirstName,
+			LastName:     d.LastName,
+			Company:      d.Company,
+			Address1:     d.Address1,
+			Address2:     d.Address2,
+			City:         d.City,
+			ProvinceCode: d.Province,
+			Zip:          d.Zip,
+			Country:      d.Country,
+			Phone:        d.Phone,
+		}
+
+		i := 0
+		for i < len(destinations) && !sameDestination(destinations[i].address, address) {
+			i++
+		}
+		if i == len(destinations) {
+			destinations = append(destinations, destination{address: address, quantities: map[uint64]int{}})
+		}
+		for _, foItem := range fo.LineItems {
+			destinations[i].quantities[foItem.LineItemId] += int(foItem.Quantity)
+		}
+	}
+	return destinations
+}
+
+// destinationOrder returns the order with only the items (and quantities) going to the destination,
+// shipped to its address, and the destination's number after the order name
+func destinationOrder(order goshopify.Order, d destination, n int) goshopify.Order {
+	var lineItems []goshopify.LineItem
+	for _, lineItem := range order.LineItems {
+		if qty, ok := d.quantities[lineItem.Id]; ok {
+			lineItem.Quantity = qty
+			lineItems = append(lineItems, lineItem)
+		}
+	}
+	order.LineItems = lineItems
+	order.ShippingAddress = d.address
+	order.Name = fmt.Sprintf("%s-%d", order.Name, n)
+	return order
+}
+
+// sameDestination reports whether two addresses are the same place for the same person, ignoring case.
+// The province and country are left out, since fulfillment orders and orders don't write them the same way.
+func sameDestination(a, b *goshopify.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	fields := func(a *goshopify.Address) []string {
+		return []string{a.FirstName, a.LastName, a.Address1, a.Address2, a.City, a.Zip}
+	}
+	aFields, bFields := fields(a), fields(b)
+	for i := range aFields {
+		if !strings.EqualFold(strings.TrimSpace(aFields[i]), strings.TrimSpace(bFields[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// oneLineAddress returns the address on one line, for a warning
+func oneLineAddress(a *goshopify.Address) string {
+	var parts []string
+	for _, s := range []string{strings.TrimSpace(a.FirstName + " " + a.LastName), a.Address1, a.Address2, a.City, a.Zip, a.Country} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/charmbracelet/log"
+)
+
+// the number of times the orders are fetched again when the --expect-order-after order isn't there,
+// waiting 1, 2, then 4 seconds
+const expectOrderRetries = 3
+
+// expectOrderWait is the longest the retries wait in all
+const expectOrderWait = (1<<expectOrderRetries - 1) * time.Second
+
+// useExpectOrderAfter parses the --expect-order-after, which only makes sense for orders newest first
+func (r *RenderCmd) useExpectOrderAfter() error {
+	if r.ExpectOrderAfter == "" {
+		return nil
+	}
+	if r.QueueOffset != nil || r.FromOldest != nil || r.OrdersFile != "" || r.FulfillmentOrderID != 0 {
+		return fmt.Errorf("--expect-order-after can't be used with --queue-offset, --from-oldest, --orders-file or --fulfillment-order-id")
+	}
+	t, err := parseTimestamp(r.ExpectOrderAfter)
+	if err != nil {
+		return fmt.Errorf("--expect-order-after: %w", err)
+	}
+	r.expectOrderAfter = t
+	return nil
+}
+
+// fetchExpectedOrders fetches the orders like fetchOrders. With --expect-order-after, it fetches them again,
+// waiting longer each time, while the newest one was placed before then, since an order that was
+// just placed can take a moment to show up in the list. It's an error if it never does.
+func (r *RenderCmd) fetchExpectedOrders(ctx context.Context, client *goshopify.Client, limit int) ([]goshopify.Order, error) {
+	for attempt := 0; ; attempt++ {
+		orders, err := r.fetchOrders(ctx, client, limit)
+		if err != nil || r.expectOrderAfter.IsZero() || newestAfter(orders, r.expectOrderAfter) {
+			return orders, err
+		}
+		if attempt == expectOrderRetries {
+			return nil, expectedOrderMissing(orders, r.expectOrderAfter)
+		}
+
+		wait := time.Duration(1<<attempt) * time.Second
+		log.Info("The expected order isn't in the list yet, trying again", "after", r.expectOrderAfter.Format(time.RFC3339), "wait", wait)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// newestAfter reports whether the newest of the orders, the first one, was placed at or after t
+func newestAfter(orders []goshopify.Order, t time.Time) bool {
+	return len(orders) > 0 && orders[0].CreatedAt != nil && !orders[0].CreatedAt.Before(t)
+}
+
+// expectedOrderMissing is the error for an --expect-order-after order that didn't show up
+func expectedOrderMissing(orders []goshopify.Order, t time.Time) error {
+	tries := expectOrderRetries + 1
+	if len(orders) == 0 || orders[0].CreatedAt == nil {
+		return fmt.Errorf("no order placed after %s showed up in %d tries", t.Format(time.RFC3339), tries)
+	}
+	return fmt.Errorf("no order placed after %s showed up in %d tries, the newest is %s from %s",
+		t.Format(time.RFC3339), tries, orders[0].Name, orders[0].CreatedAt.Format(time.RFC3339))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/charmbracelet/log"
+)
+
+// how long a --post-hook command gets before it's stopped
+const postHookTimeout = time.Minute
+
+// postHookData is what a --post-hook command is executed with, each value quoted for the shell
+type postHookData struct {
+	Name string
+	File string
+}
+
+// parsePostHook returns the --post-hook template, or nil if there isn't one
+func parsePostHook(hook string) (*template.Template, error) {
+	if hook == "" {
+		return nil, nil
+	}
+	t, err := template.New("--post-hook").Option("missingkey=error").Parse(hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --post-hook template: %w", err)
+	}
+	return t, nil
+}
+
+// runPostHooks runs the --post-hook command once for each file writeSlips wrote, logging its output.
+// A file with more than one order in it, the --combine PDF or the --zip, gets their names joined
+// with commas, so a hook like lp {{.File}} doesn't print the batch once per order.
+// A command that fails only gets a warning, which fails the run with --strict.
+func (r *RenderCmd) runPostHooks(orders []goshopify.Order) {
+	if r.postHook == nil {
+		return
+	}
+	if !r.combine() {
+		orders = firstOrder(orders)
+	}
+
+	var files []string
+	names := map[string][]string{}
+	for _, order := range orders {
+		file, err := r.slipFile(order)
+		if err != nil {
+			warn("Skipping the post hook", "order", order.Name, "err", err)
+			continue
+		}
+		if _, ok := names[file]; !ok {
+			files = append(files, file)
+		}
+		names[file] = append(names[file], order.Name)
+	}
+
+	for _, file := range files {
+		name := strings.Join(names[file], ",")
+		var cmdline bytes.Buffer
+		if err := r.postHook.Execute(&cmdline, postHookData{Name: shellQuote(name), File: shellQuote(file)}); err != nil {
+			warn("Skipping the post hook", "order", name, "err", err)
+			continue
+		}
+
+		output, err := runShell(cmdline.String())
+		if err != nil {
+			warn("Post hook failed", "order", name, "err", err, "output", output)
+			continue
+		}
+		log.Info("Ran the post hook", "order", name, "output", output)
+	}
+}
+
+// slipFile returns the file the order's slip was written to: its own file in the --output-dir,
+// the --zip, or the --outfile (the first one, with copies in files of their own), which is its URL in a bucket
+func (r *RenderCmd) slipFile(order goshopify.Order) (string, error) {
+	if r.OutputDir != "" {
+		return outfileName(r.OutputDir, orderFileTemplate, order)
+	}
+	if r.Zip != "" {
+		return r.Zip, nil
+	}
+	if r.bucket != nil {
+		return r.bucket.url(r.outFilenames()[0]), nil
+	}
+	return r.outFilenames()[0], nil
+}
+
+// runShell runs the command line with the shell and returns what it printed, trimmed
+func runShell(cmdline string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postHookTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/c", cmdline)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", cmdline)
+	}
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("stopped after %s", postHookTimeout)
+	}
+	return strings.TrimSpace(string(output)), err
+}
+
+// shellQuote quotes the value so the shell passes it to the command as is,
+// since an order name like #1001 would otherwise start a comment
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
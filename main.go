@@ -2,58 +2,126 @@ package main
 
 import (
 	"context"
-	"embed"
+	"crypto/sha256"
+	"errors"
 	"fmt"
-	"io/fs"
+	"io"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
+	"text/tabwriter"
+	"text/template"
 	"time"
 
 	"github.com/alecthomas/kong"
 	goshopify "github.com/bold-commerce/go-shopify/v4"
 	"github.com/charmbracelet/log"
 	"github.com/getsops/sops/v3/decrypt"
-	"github.com/signintech/gopdf"
+	"github.com/rahji/packingslipper/slip"
 	"gopkg.in/yaml.v2"
 )
 
-//go:embed arialrounded.ttf
-//go:embed arialroundedbold.ttf
-var EmbeddedFile embed.FS
-
 type CLIFlags struct {
-	OutFilename     string `kong:"default='packingslip.pdf',name='outfile',help='Output PDF filename'"`
-	OrderOffset     int    `kong:"default=0,name='offset',help='Offset from most recent order to retrieve'"`
-	ConfigFilename  string `kong:"name='config',help='Configuration YAML file (default: ~/.config/packingslipper/configuration.yaml)'"`
-	SecretsFilename string `kong:"name='secrets',help='Encrypted secrets YAML file (default: ~/.config/packingslipper/secrets.enc.yaml)'"`
-	Verbose         bool   `kong:"name='verbose',help='Display extra information on STDOUT'"`
+	ConfigFilenames []string      `kong:"name='config',sep=',',help='Configuration YAML files, merged in order (default: ~/.config/packingslipper/configuration.yaml)'"`
+	SecretsFilename string        `kong:"name='secrets',help='Encrypted secrets YAML file (default: ~/.config/packingslipper/secrets.enc.yaml)'"`
+	Verbose         bool          `kong:"name='verbose',help='Display extra information on STDOUT'"`
+	Profile         string        `kong:"name='profile',help='Look for the default config and secrets in ~/.config/packingslipper/<profile>'"`
+	DecryptTimeout  time.Duration `kong:"name='decrypt-timeout',default='30s',help='Give up decrypting a sops file (e.g. waiting on a KMS) after this long, 0 for no limit'"`
+
+	Render         RenderCmd         `kong:"cmd,default='withargs',help='Create a packing slip PDF (default)'"`
+	TestConnection TestConnectionCmd `kong:"cmd,name='test-connection',help='Check the Shopify credentials without rendering anything'"`
+	Config         ConfigCmd         `kong:"cmd,help='Work with the configuration'"`
+	TUI            TUICmd            `kong:"cmd,name='tui',help='Pick one of the recent orders from a list and render it'"`
 }
 
-type FontStyle int
+type RenderCmd struct {
+	OutFilename    string   `kong:"name='outfile',help='Output filename, or - for STDOUT (default: packingslip.pdf, or STDOUT with --format text or csv). The file name (not the directory) can be a text/template of the order, like {{.Name}}-{{.Date}}.pdf. An s3://bucket/key or gs://bucket/key uploads it to the bucket'"`
+	OutputDir      string   `kong:"name='output-dir',help='Also write a PDF for each order to this directory, named after the order (like 1001.pdf), e.g. to archive them while printing the --combine PDF'"`
+	Zip            string   `kong:"name='zip',help='Write a PDF for each order into this ZIP archive instead of the --outfile, named with the --outfile template if it has one (default: like 1001.pdf), or - for STDOUT'"`
+	Manifest       bool     `kong:"name='manifest',help='Add a manifest.csv of the orders, with the --csv-columns, to the --zip'"`
+	Format         string   `kong:"name='format',enum='pdf,text,csv',default='pdf',help='Output format: ${enum} (csv is a manifest with a row per order, for --count orders)'"`
+	CSVColumns     []string `kong:"name='csv-columns',sep=',',default='name,date,customer,items,weight,price',help='Columns of the --format csv manifest: name, date, customer, email, country, items, weight (grams), price or currency'"`
+	OrderOffset    int      `kong:"default=0,name='offset',help='Offset from most recent order to retrieve'"`
+	ShowBilling    bool     `kong:"name='show-billing',help='Add a BILL TO block after the SHIP TO block'"`
+	Preview        bool     `kong:"name='preview',help='Open the PDF in the default viewer after writing it'"`
+	Draft          bool     `kong:"name='draft',help='Use draft orders instead of orders'"`
+	SortItems      string   `kong:"name='sort-items',enum='original,sku,name,quantity,location',default='original',help='Order of the line items: ${enum}'"`
+	Fit            bool     `kong:"name='fit',help='Shrink the text until everything fits on one label'"`
+	LayoutInfo     bool     `kong:"name='layout-info',help='Print where each section ended up on the page to STDERR'"`
+	Table          bool     `kong:"name='table',help='Lay the line items out in Qty, Item and SKU columns, for wider labels'"`
+	ShowVendor     bool     `kong:"name='show-vendor',help='Add the vendor to each line item'"`
+	NetQuantities  bool     `kong:"name='net-quantities',help='Take refunded quantities off the line items, and leave out the ones refunded completely'"`
+	StripEmoji     bool     `kong:"name='strip-emoji',help='Leave emoji out of item names, since most fonts draw them as empty boxes'"`
+	GroupByVendor  bool     `kong:"name='group-by-vendor',help='Group the line items under vendor headings'"`
+	PDFMetadata    bool     `kong:"name='pdf-metadata',help='Put the order, its ID and date, and the shop in the PDF document properties'"`
+	ShowHash       bool     `kong:"name='show-hash',help='Print a short hash of the order at the bottom, to spot reprints of a changed order'"`
+	Preset         string   `kong:"name='preset',help='Lay this preset from the config over the rest of it, like a picking or gift slip design'"`
+	Monochrome     bool     `kong:"name='monochrome',help='Print everything in black, with the logo thresholded to black and white, for thermal printers'"`
+	Compact        bool     `kong:"name='compact',help='Render a minimal slip for tiny labels (2x1in unless the page size is set): just the order and the name, or the compact fields from the config'"`
+	TextWidth      float64  `kong:"name='text-width',help='Wrap the text this many points from the left margin, to leave room beside it'"`
+	Watermark      string   `kong:"name='watermark',help='Write this text (like DRAFT or REPRINT) across the page behind the slip'"`
+	BatchTotal     int      `kong:"name='batch-total',help='Number the slips \"Slip N of TOTAL\", counting from --offset 0 as slip 1'"`
+	ValidateSize   bool     `kong:"name='validate-size',help='Warn if the page size does not match a standard label size (always done with --verbose)'"`
+	Redact         bool     `kong:"name='redact',help='Mask the customer names, address lines, email and phone, for sample slips'"`
+	SkipTest       bool     `kong:"name='skip-test',help='Leave out test orders (marked as a test or paid with the Bogus Gateway) instead of rendering them with a DO NOT SHIP banner'"`
+	Strict         bool     `kong:"name='strict',help='Exit with an error if there were any warnings, like a missing address or content overflowing the page'"`
+	Copies         int      `kong:"name='copies',default=1,help='Make this many copies of each slip: pages in one PDF with --combine or STDOUT, otherwise a file each (name-1.pdf, name-2.pdf...)'"`
+	Combine        bool     `kong:"name='combine',help='Put --count orders in one PDF, one page each, starting at --offset'"`
+	Cover          bool     `kong:"name='cover',help='Start the --combine PDF with a page listing its orders (name, customer and items) to check off while packing'"`
+	PerDestination string   `kong:"name='per-destination',enum='off,warn,split',default='off',help='For orders shipping to more than one address: off to not check, warn and use the shipping address, or split into a slip per address. Checking takes a request per order and the read_merchant_managed_fulfillment_orders scope (${enum})'"`
+	ShowSource     bool     `kong:"name='show-source',help='Put the sales channel the order came from (like Online Store or Point of Sale) under the date'"`
+	ShowRisk       bool     `kong:"name='show-risk',help='Put the Shopify fraud risk recommendation (accept, investigate or cancel) in a colored banner at the top'"`
+	ShowPayment    bool     `kong:"name='show-payment',help='Add how the order was paid for: the gateway, card and amount of each payment'"`
+	Metafields     []string `kong:"name='metafield',help='Add the value of this order metafield (NAMESPACE.KEY) to the slip, can be repeated'"`
+	Watch          bool     `kong:"name='watch',help='Keep running and render the same order again whenever the config or logo changes'"`
+	Resume         bool     `kong:"name='resume',help='Skip the orders of this batch that an earlier run with the same options already rendered, and record each one as its --output-dir file is written'"`
+	NoResume       bool     `kong:"name='no-resume',help='Forget the orders --resume recorded for this batch, so they are all rendered again'"`
+	SkipDuplicates bool     `kong:"name='skip-duplicates',help='Skip the orders any run rendered within --duplicate-window, and record the ones rendered now'"`
+	Force          bool     `kong:"name='force',help='Render the orders --skip-duplicates would skip, and record them again'"`
+	PostHook       string   `kong:"name='post-hook',help='Run this shell command after each slip file is written, a text/template with the order {{.Name}} (names joined with commas for a combined file) and the {{.File}} (quoted for the shell). A failure is a warning'"`
+	MetricsFile    string   `kong:"name='metrics-file',help='Write counts of the slips rendered and errors, and the render times, to this file in the Prometheus text format'"`
 
-const (
-	Bold FontStyle = iota
-	Regular
-)
+	DuplicateWindow    time.Duration `kong:"name='duplicate-window',default='24h',help='How long after an order is rendered --skip-duplicates skips it'"`
+	UpdatedAfter       string        `kong:"name='updated-after',help='Only use orders updated since this time, like 2024-05-01 or 2024-05-01T15:04:05-07:00 (local time without a zone)'"`
+	ExpectOrderAfter   string        `kong:"name='expect-order-after',help='Fetch the orders again a few times, waiting longer each time, while the newest was placed before this time, for an order that was just placed'"`
+	QueueOffset        *int          `kong:"name='queue-offset',help='Offset into the fulfillment queue instead: unfulfilled orders, oldest first, so 0 is the next one to pack'"`
+	FromOldest         *int          `kong:"name='from-oldest',help='Offset from the oldest order instead of the most recent, so 0 is the oldest. Use it instead of --offset'"`
+	FulfillmentOrderID uint64        `kong:"name='fulfillment-order-id',help='Render the items of this fulfillment order, with its location as the FROM address, instead of a whole order'"`
+	LocationID         uint64        `kong:"name='location-id',help='Only render the items assigned to this location, with it as the FROM address, leaving out orders with nothing to ship from it'"`
+	OrdersFile         string        `kong:"name='orders-file',help='Render the orders listed in this file, one order number (like #1001) or ID per line, into one PDF like --combine. Blank lines and comments starting with \"# \" are skipped'"`
+	CustomerEmail      string        `kong:"name='customer-email',help='Only use the orders of the customer with this email address, rendering --count of them into one PDF like --combine'"`
+	Tag                string        `kong:"name='tag',help='Use the orders with this tag, rendering up to --count of them into one PDF like --combine'"`
+	Query              string        `kong:"name='query',help='Use the orders matching this Shopify search query, like \"financial_status:paid fulfillment_status:unfulfilled\", rendering up to --count of them into one PDF like --combine'"`
+	ListOrders         bool          `kong:"name='list-orders',help='Print the recent orders and their offsets instead of rendering'"`
+	Count              int           `kong:"name='count',help='Number of orders to list with --list-orders or tui, or render with --combine (default 10)'"`
+	Status             string        `kong:"name='status',enum='open,closed,cancelled,any',default='any',help='Only use orders with this status: ${enum}'"`
+	FulfillmentStatus  string        `kong:"name='fulfillment-status',enum=',shipped,partial,unshipped,unfulfilled,any',default='',help='Only use orders with this fulfillment status: shipped, partial, unshipped, unfulfilled or any'"`
 
-var fontStyleName = map[FontStyle]string{
-	Bold:    "bold",
-	Regular: "regular",
+	// updatedAfter is --updated-after once it's parsed
+	updatedAfter time.Time
+	// expectOrderAfter is --expect-order-after once it's parsed
+	expectOrderAfter time.Time
+	// zipEntries names the PDFs in the --zip
+	zipEntries *template.Template
+	// postHook is the --post-hook once it's parsed
+	postHook *template.Template
+	// resume is the --resume state, which writeOrderFiles adds each order to as its slip is written
+	resume *resumeState
+	// picked is the order the tui is rendering, fetched already when it listed the orders
+	picked *goshopify.Order
+	// bucket is where the slips are uploaded with an s3:// or gs:// --outfile
+	bucket *bucketTarget
 }
 
-type Config struct {
-	Logo struct {
-		Filename      string `yaml:"filename"`
-		VerticalSpace int    `yaml:"vertical-space"`
-	} `yaml:"logo"`
+// the number of orders --list-orders and --combine use without a --count
+const defaultListCount = 10
 
-	Text struct {
-		Salutation    string `yaml:"salutation"`
-		Signature     string `yaml:"signature"`
-		VerticalSpace int    `yaml:"vertical-space"`
-	} `yaml:"text"`
-}
+type TestConnectionCmd struct{}
 
 type Secrets struct {
 	API struct {
@@ -63,239 +131,955 @@ type Secrets struct {
 }
 
 type AllConfig struct {
-	Config  Config
+	Config  slip.Config
 	Secrets Secrets
 }
 
-// myPdf embeds gopdf.GoPdf so I can create a WriteLine method later
-// https://stackoverflow.com/questions/28800672/how-to-add-new-methods-to-an-existing-type-in-go
-type myPdf struct {
-	*gopdf.GoPdf
-}
-
-const pageWidth = 144  // points
-const pageHeight = 504 // points
-const lineSpacing = 13
-const fontSize = 10
-
-// loadEmbeddedFont returns an fs.File from an embedded ttf file
-func loadEmbeddedFont(fn string) (fs.File, error) {
-	f, err := EmbeddedFile.Open(fn)
+// LoadConfig loads the config and secrets yaml files and returns structs
+func LoadConfig(configPaths []string, secretsPath string) (*AllConfig, error) {
+	config, err := loadConfigFiles(configPaths)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-	return f, nil
-}
 
-// createPDF sets up a gopdf.GoPdf document for the packing slip label
-func createPDF() (*myPdf, error) {
-	// create the pdf struct
-	pdf := &myPdf{&gopdf.GoPdf{}}
-
-	// load the font files
-	boldFile, err := loadEmbeddedFont("arialroundedbold.ttf")
+	secrets, err := loadSecrets(secretsPath)
 	if err != nil {
 		return nil, err
 	}
-	regFile, err := loadEmbeddedFont("arialrounded.ttf")
-	if err != nil {
-		return nil, err
+
+	return &AllConfig{
+		Config:  *config,
+		Secrets: *secrets,
+	}, nil
+}
+
+// loadConfigFiles loads the configuration yaml files in order, decrypting any that are sops encrypted.
+// Each file is unmarshalled over the previous ones, so later files only
+// override the fields they actually set.
+func loadConfigFiles(configPaths []string) (*slip.Config, error) {
+	var config slip.Config
+	for _, configPath := range configPaths {
+		configData, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		if isSopsEncrypted(configData) {
+			configData, err = decryptConfig(configPath, configData)
+			if err != nil {
+				return nil, explainDecryptError("config "+configPath, err)
+			}
+		}
+
+		// strict, so that a misspelled key is an error instead of a silent zero value
+		if err := yaml.UnmarshalStrict(configData, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, tidyYAMLError(err))
+		}
 	}
 
-	// create font containers for the two fonts
-	regFontContainer := &gopdf.FontContainer{}
-	err = regFontContainer.AddTTFFontByReader("regular", regFile)
-	if err != nil {
-		return nil, err
+	return &config, nil
+}
+
+// decryptedConfig is a config file's decrypted contents, with a hash of the encrypted ones they came from
+type decryptedConfig struct {
+	sum       [sha256.Size]byte
+	cleartext []byte
+}
+
+// decryptedConfigs holds the latest decryption of each config file by its path, so --watch
+// doesn't go back to the key service for files that haven't changed. An edited file replaces
+// its entry rather than adding one, and it's only ever kept in memory.
+var decryptedConfigs = map[string]decryptedConfig{}
+
+// decryptConfig decrypts a sops encrypted config file, reusing the result for the same contents
+func decryptConfig(path string, data []byte) ([]byte, error) {
+	sum := sha256.Sum256(data)
+	if cached, ok := decryptedConfigs[path]; ok && cached.sum == sum {
+		return cached.cleartext, nil
 	}
-	boldFontContainer := &gopdf.FontContainer{}
-	err = boldFontContainer.AddTTFFontByReader("bold", boldFile)
+	cleartext, err := decryptWithRetry(func() ([]byte, error) {
+		return decrypt.Data(data, "yaml")
+	})
 	if err != nil {
 		return nil, err
 	}
+	decryptedConfigs[path] = decryptedConfig{sum: sum, cleartext: cleartext}
+	return cleartext, nil
+}
 
-	labelSize := &gopdf.Rect{W: pageWidth, H: pageHeight}
-	pdf.Start(gopdf.Config{PageSize: *labelSize})
-	pdf.AddPage()
+// unknownFieldPattern matches the end of yaml's unknown field error, which spells out the whole struct type
+var unknownFieldPattern = regexp.MustCompile(`field (\S+) not found in type .*$`)
 
-	// load the fonts from their containers
-	if err := pdf.AddTTFFontFromFontContainer("regular", regFontContainer); err != nil {
-		return nil, err
+// tidyYAMLError shortens yaml's unknown field errors to just the line and the field name
+func tidyYAMLError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
 	}
-	if err := pdf.AddTTFFontFromFontContainer("bold", boldFontContainer); err != nil {
-		return nil, err
+	lines := make([]string, len(typeErr.Errors))
+	for i, e := range typeErr.Errors {
+		lines[i] = unknownFieldPattern.ReplaceAllString(e, "unknown field $1")
 	}
+	return errors.New(strings.Join(lines, "; "))
+}
 
-	if err := pdf.SetFont("regular", "", fontSize); err != nil {
-		return nil, err
+// loadSecrets decrypts and loads the secrets yaml file
+func loadSecrets(secretsPath string) (*Secrets, error) {
+	secretsData, err := decryptWithRetry(func() ([]byte, error) {
+		return decrypt.File(secretsPath, "yaml")
+	})
+	if err != nil {
+		return nil, explainDecryptError("secrets", err)
 	}
 
-	return pdf, nil
-}
+	var secrets Secrets
+	if err := yaml.Unmarshal(secretsData, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
 
-// writeLine writes a line to the PDF.
-// It wraps long strings at based on pageWidth-rightMargin.
-// More than 1 trailing newline characters are converted to additional line breaks.
-func (p *myPdf) writeLine(s string) {
-	trimmed := strings.TrimRight(s, "\n")
-	newlines := len(s) - len(trimmed)
+	return &secrets, nil
+}
 
-	// if there is any text after trimming the newlines
-	// then split it at the pageWidth before writing it to a cell
-	if trimmed != "" {
-		texts, _ := p.SplitTextWithWordWrap(trimmed, pageWidth-p.MarginRight())
-		for _, text := range texts {
-			_ = p.Cell(nil, text)
-			p.Br(lineSpacing)
-		}
-	}
+// how long each Shopify request can take, and how many times in all one is tried when Shopify
+// rate limits it (waiting as long as it asks) or is unavailable
+const (
+	requestTimeout  = 10 * time.Second
+	requestAttempts = 4
+)
 
-	if newlines > 1 {
-		p.Br(lineSpacing * float64(newlines-1))
-	}
+// newClient creates a shopify api client from the secrets. Each request has its own timeout,
+// so a batch that makes a request or more per order isn't cut off partway.
+func newClient(secrets Secrets) (*goshopify.Client, error) {
+	app := goshopify.App{}
+	return goshopify.NewClient(app, secrets.API.ShopName, secrets.API.Token,
+		goshopify.WithRetry(requestAttempts),
+		goshopify.WithHTTPClient(&http.Client{Timeout: requestTimeout}),
+	)
 }
 
-// changeFontStyle sets the font to either bold or regular
-// it does a log.Fatal if it can't be done
-func (p *myPdf) changeFontStyle(s FontStyle) {
-	err := p.SetFont(fontStyleName[s], "", fontSize)
+// setDefaultPaths uses the default config and secrets file location in ~/.config/packingslipper
+// (or the profile's directory inside it) if those flags aren't specified
+func (cli *CLIFlags) setDefaultPaths() error {
+	home, err := os.UserHomeDir()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	dir := filepath.Join(home, ".config", "packingslipper")
+	if cli.Profile != "" {
+		dir = filepath.Join(dir, cli.Profile)
+	}
+	if len(cli.ConfigFilenames) == 0 {
+		cli.ConfigFilenames = []string{filepath.Join(dir, "configuration.yaml")}
+	}
+	if cli.SecretsFilename == "" {
+		cli.SecretsFilename = filepath.Join(dir, "secrets.enc.yaml")
+	}
+	return nil
 }
 
-// LoadConfig loads the config and secrets yaml files and returns structs
-func LoadConfig(configPath, secretsPath string) (*AllConfig, error) {
-	// Load plain configuration
-	configData, err := os.ReadFile(configPath)
+// Run checks that the secrets work by fetching the shop and its order count
+func (t *TestConnectionCmd) Run(cli *CLIFlags) error {
+	if cli.Verbose {
+		log.Info("Using config", "secrets", cli.SecretsFilename)
+	}
+
+	secrets, err := loadSecrets(cli.SecretsFilename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return err
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(configData, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	client, err := newClient(*secrets)
+	if err != nil {
+		return err
 	}
 
-	// Load and decrypt secrets
-	secretsData, err := decrypt.File(secretsPath, "yaml")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	shop, err := client.Shop.Get(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt secrets file: %w", err)
+		return fmt.Errorf("failed to get shop: %w", err)
 	}
 
-	var secrets Secrets
-	if err := yaml.Unmarshal(secretsData, &secrets); err != nil {
-		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	count, err := client.Order.Count(ctx, goshopify.OrderCountOptions{Status: "any"})
+	if err != nil {
+		return fmt.Errorf("failed to count orders: %w", err)
 	}
 
-	return &AllConfig{
-		Config:  config,
-		Secrets: secrets,
-	}, nil
+	fmt.Printf("Connected to %s (%s)\n", shop.Name, shop.MyshopifyDomain)
+	fmt.Printf("Orders: %d\n", count)
+	return nil
 }
 
 func main() {
 	var cli CLIFlags
-	kong.Parse(&cli)
+	ctx := kong.Parse(&cli)
 
-	// usee the default config and secrets file location in ~/.config/packingslipper
-	// if those flags aren't specified
-	home, err := os.UserHomeDir()
-	if err != nil {
+	if cli.Verbose {
+		slip.Logger = log.Default()
+	}
+	decryptTimeout = cli.DecryptTimeout
+
+	if err := cli.setDefaultPaths(); err != nil {
 		log.Fatal(err)
 	}
-	if cli.ConfigFilename == "" {
-		cli.ConfigFilename = filepath.Join(home, ".config", "packingslipper", "configuration.yaml")
+
+	if err := ctx.Run(&cli); err != nil {
+		log.Fatal(err)
 	}
-	if cli.SecretsFilename == "" {
-		cli.SecretsFilename = filepath.Join(home, ".config", "packingslipper", "secrets.enc.yaml")
+}
+
+// Run creates the packing slip PDF for the selected order.
+// With --strict, any warning along the way makes it fail once it's done.
+// With --metrics-file, the metrics are written whether it worked or not.
+func (r *RenderCmd) Run(cli *CLIFlags) error {
+	if r.Strict {
+		// the warnings have to be seen to be fixed, verbose or not
+		slip.Logger = log.New(warningCounter{w: os.Stderr})
 	}
+
+	err := r.run(cli)
+	if err != nil {
+		metrics.fail(metrics.stage)
+	}
+	if r.MetricsFile != "" {
+		if err := writeMetricsFile(r.MetricsFile, metrics); err != nil {
+			log.Error(err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.Strict && warnings > 0 {
+		return fmt.Errorf("failing because of %d warning(s) with --strict", warnings)
+	}
+	return nil
+}
+
+// run does the work of Run
+func (r *RenderCmd) run(cli *CLIFlags) error {
+	if err := r.useOrderFlags(); err != nil {
+		return err
+	}
+
+	if r.ListOrders {
+		metrics.stage = "api"
+		return r.listOrders(cli)
+	}
+
+	if r.OutFilename == "" {
+		r.OutFilename = "packingslip.pdf"
+		if r.Format == "text" || r.Format == "csv" {
+			r.OutFilename = "-"
+		}
+	}
+	if r.Zip != "" && r.Format != "pdf" {
+		return fmt.Errorf("--zip only works with --format pdf")
+	}
+	if r.Zip != "" && r.Preview {
+		return fmt.Errorf("--preview can't be used with --zip")
+	}
+	if r.LocationID != 0 && (r.FulfillmentOrderID != 0 || r.Draft) {
+		return fmt.Errorf("--location-id can't be used with --fulfillment-order-id or --draft")
+	}
+	if (r.Resume || r.NoResume) && !r.combine() {
+		return fmt.Errorf("--resume and --no-resume only work with a batch: --combine, --query, --tag, --customer-email or --orders-file")
+	}
+	if r.Resume && r.Watch {
+		return fmt.Errorf("--resume can't be used with --watch")
+	}
+	// the orders are recorded as their own files are written, so a run that stops partway keeps them
+	if r.Resume && r.OutputDir == "" {
+		return fmt.Errorf("--resume needs --output-dir, so each order's slip is in a file of its own")
+	}
+	if r.SkipDuplicates && r.Watch {
+		return fmt.Errorf("--skip-duplicates can't be used with --watch")
+	}
+	if r.Force && !r.SkipDuplicates {
+		return fmt.Errorf("--force only works with --skip-duplicates")
+	}
+	if r.DuplicateWindow <= 0 {
+		return fmt.Errorf("--duplicate-window has to be more than 0")
+	}
+	if r.Cover && (!r.combine() || r.Format != "pdf" || r.Zip != "") {
+		return fmt.Errorf("--cover only works with --combine (or another batch) and --format pdf, and not with --zip")
+	}
+	if r.Manifest && r.Zip == "" {
+		return fmt.Errorf("--manifest only works with --zip (use --format csv for a manifest on its own)")
+	}
+	if r.Format == "csv" || r.Manifest {
+		if err := checkManifestColumns(r.CSVColumns); err != nil {
+			return err
+		}
+	}
+	if r.Copies < 1 {
+		return fmt.Errorf("--copies has to be at least 1")
+	}
+	if r.Copies > 1 && r.Format == "csv" {
+		return fmt.Errorf("--copies can't be used with --format csv")
+	}
+	if r.OutputDir != "" && r.Format != "pdf" {
+		return fmt.Errorf("--output-dir only works with --format pdf")
+	}
+	if err := r.useBucket(); err != nil {
+		return err
+	}
+	defer r.bucket.cleanup()
+	outfileTemplate, err := parseOutfileTemplate(r.OutFilename)
+	if err != nil {
+		return err
+	}
+	r.postHook, err = parsePostHook(r.PostHook)
+	if err != nil {
+		return err
+	}
+
 	if cli.Verbose {
-		log.Info("Using config", "configuration", cli.ConfigFilename)
+		for _, fn := range cli.ConfigFilenames {
+			log.Info("Using config", "configuration", fn)
+		}
 		log.Info("Using config", "secrets", cli.SecretsFilename)
 	}
 
 	// load the configuration files
-	cfg, err := LoadConfig(cli.ConfigFilename, cli.SecretsFilename)
+	cfg, err := LoadConfig(cli.ConfigFilenames, cli.SecretsFilename)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	if err := r.applyPreset(&cfg.Config); err != nil {
+		return err
+	}
+	r.applyFlags(&cfg.Config)
+	cfg.Config.PDFMetadata.Shop = cfg.Secrets.API.ShopName
+	if err := cfg.Config.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if r.ValidateSize || cli.Verbose {
+		warning, err := cfg.Config.PageSizeWarning()
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			warn("Page size may not match the label printer", "problem", warning)
+		}
+	}
+	for _, warning := range cfg.Config.FontSizeWarnings() {
+		warn("Text may be too small to read", "problem", warning)
 	}
 
-	// create the blank label
-	p, err := createPDF()
+	// create a new shopify api client
+	metrics.stage = "api"
+	client, err := newClient(cfg.Secrets)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	// create a new shopify app and api client
-	app := goshopify.App{}
-	client, err := goshopify.NewClient(app, cfg.Secrets.API.ShopName, cfg.Secrets.API.Token)
+	// with --resume, Ctrl-C stops the Shopify requests, and lets slips that are being written finish
+	// so they're recorded, rather than killing the run
+	ctx := context.Background()
+	if r.Resume {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+	}
+
+	var orders []goshopify.Order
+	if r.OrdersFile != "" {
+		orders, err = r.fetchOrdersFile(ctx, client)
+		if err != nil {
+			return err
+		}
+	} else if r.FulfillmentOrderID != 0 {
+		if r.Draft {
+			return fmt.Errorf("--fulfillment-order-id can't be used with --draft")
+		}
+		order, from, err := fetchFulfillmentOrder(ctx, client, r.FulfillmentOrderID)
+		if err != nil {
+			return err
+		}
+		orders = []goshopify.Order{order}
+		cfg.Config.From = from
+	} else if r.picked != nil {
+		orders = []goshopify.Order{*r.picked}
+	} else {
+		orders, err = r.selectOrders(ctx, client)
+		if err != nil || orders == nil {
+			return err
+		}
+	}
+
+	if r.LocationID != 0 {
+		orders, cfg.Config.From, err = r.fetchLocationItems(ctx, client, orders)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.warnIncomplete(orders)
+	if r.SkipTest || r.Strict {
+		orders = skipTestOrders(orders)
+		if len(orders) == 0 {
+			return fmt.Errorf("not rendering test orders with --skip-test or --strict")
+		}
+	}
+	if r.Resume || r.NoResume {
+		r.resume, err = loadResumeState(r.resumeKey(cfg.Secrets.API.ShopName), r.NoResume)
+		if err != nil {
+			return err
+		}
+	}
+	if r.Resume {
+		left := r.resume.remaining(orders)
+		if len(left) == 0 {
+			log.Info("All the orders of this batch were already rendered, use --no-resume to render them again", "count", len(orders))
+			return nil
+		}
+		if skipped := len(orders) - len(left); skipped > 0 {
+			log.Info("Skipping the orders this batch already rendered", "count", skipped)
+		}
+		orders = left
+	}
+	var printed *printedOrders
+	if r.SkipDuplicates {
+		printed, err = loadPrintedOrders(cfg.Secrets.API.ShopName)
+		if err != nil {
+			return err
+		}
+		// held until the orders are recorded, so an overlapping run can't render them too
+		defer printed.unlock()
+	}
+	if r.SkipDuplicates && !r.Force {
+		orders = printed.notRecent(orders, r.DuplicateWindow)
+		if len(orders) == 0 {
+			log.Info("All the orders were already rendered within --duplicate-window, use --force to render them again", "window", r.DuplicateWindow)
+			return nil
+		}
+	}
+	// an order picked without asking for one is always named, so it's clear which slip came out
+	if r.mostRecent() {
+		log.Info("Rendering the most recent order", "order", orders[0].Name)
+	} else if cli.Verbose {
+		if r.combine() {
+			log.Info("Got orders", "first", orders[0].Name, "last", orders[len(orders)-1].Name, "count", len(orders))
+		} else {
+			log.Info("Got orders", "latest", orders[0].Name)
+		}
+	}
+
+	// metafields take an extra request per order, so they're only fetched when the slip uses them
+	if len(cfg.Config.Metafields.Keys) > 0 || cfg.Config.Delivery.Metafield != "" {
+		if err := r.fetchMetafields(ctx, client, orders); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Config.Items.Backorder.Show && r.Draft {
+		warn("Draft orders have no fulfillable quantities, leaving the backorder lines out")
+		cfg.Config.Items.Backorder.Show = false
+	}
+	if cfg.Config.Payment.Show {
+		if err := r.fetchTransactions(ctx, client, orders); err != nil {
+			return err
+		}
+	}
+	if cfg.Config.Risk.Show {
+		cfg.Config.Risk.Recommendations, err = r.fetchRisks(ctx, client, orders)
+		if err != nil {
+			return err
+		}
+	}
+
+	// after the other requests, so the slips of each destination share what they got
+	orders, err = r.splitDestinations(ctx, client, orders)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if r.Redact {
+		for i := range orders {
+			orders[i] = redactOrder(orders[i])
+		}
+	}
+
+	metrics.stage = "render"
+	// a combined PDF is named after its first order, and the PDFs in a --zip after theirs
+	if r.Zip != "" {
+		r.zipEntries = orderFileTemplate
+		if outfileTemplate != nil {
+			r.zipEntries = outfileTemplate
+		}
+	} else if outfileTemplate != nil {
+		r.OutFilename, err = outfileName(filepath.Dir(r.OutFilename), outfileTemplate, orders[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.Watch {
+		return r.watch(cli, orders, cfg.Config)
+	}
+
+	if err := r.writeSlips(orders, cfg.Config); err != nil {
+		return err
+	}
+	if r.bucket != nil {
+		if err := r.uploadSlips(); err != nil {
+			return err
+		}
+	}
+	r.runPostHooks(orders)
+	if r.SkipDuplicates {
+		if err := printed.record(orders, r.DuplicateWindow); err != nil {
+			return fmt.Errorf("failed to record the rendered orders for --skip-duplicates: %w", err)
+		}
+	}
+	if cli.Verbose && r.Zip != "" && r.Zip != "-" {
+		if info, err := os.Stat(r.Zip); err == nil {
+			log.Info("Wrote slips", "zip", r.Zip, "bytes", info.Size())
+		}
+	}
+	// gopdf only embeds the glyphs that are used, so this is mostly the logo
+	if cli.Verbose && r.OutFilename != "-" && r.Zip == "" && r.bucket == nil {
+		for _, fn := range r.outFilenames() {
+			if info, err := os.Stat(fn); err == nil {
+				log.Info("Wrote slip", "file", fn, "bytes", info.Size())
+			}
+		}
+	}
+
+	// the copies are all the same, so the first one will do for a preview
+	if r.Preview && r.OutFilename != "-" {
+		return openFile(r.outFilenames()[0])
+	}
+	return nil
+}
+
+// useOrderFlags checks and applies the flags that pick the orders, before any are fetched
+func (r *RenderCmd) useOrderFlags() error {
+	if err := r.useOrdersFile(); err != nil {
+		return err
+	}
+	if err := r.useQueueOffset(); err != nil {
+		return err
+	}
+	if err := r.useFromOldest(); err != nil {
+		return err
+	}
+	if r.UpdatedAfter != "" {
+		t, err := parseTimestamp(r.UpdatedAfter)
+		if err != nil {
+			return fmt.Errorf("--updated-after: %w", err)
+		}
+		r.updatedAfter = t
+	}
+	return r.useExpectOrderAfter()
+}
 
-	orders, err := client.Order.List(ctx, goshopify.OrderListOptions{Status: "any"})
+// selectOrders fetches the order at --offset, or --count of them starting there with --combine.
+// It returns no orders and no error if it already told the user there weren't any.
+func (r *RenderCmd) selectOrders(ctx context.Context, client *goshopify.Client) ([]goshopify.Order, error) {
+	count := 1
+	if r.combine() {
+		count = r.Count
+		if count <= 0 {
+			count = defaultListCount
+		}
+	}
+
+	orders, err := r.fetchExpectedOrders(ctx, client, r.OrderOffset+count)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	if r.CustomerEmail != "" && len(orders) == 0 {
+		fmt.Printf("No orders found for %s\n", r.CustomerEmail)
+		return nil, nil
+	}
+	if r.searching() && len(orders) == 0 {
+		return nil, r.noMatches()
+	}
+	if len(orders) == 0 {
+		return nil, r.noOrders()
+	}
+	if r.OrderOffset >= len(orders) {
+		return nil, fmt.Errorf("offset %d is out of range, only %d orders were found", r.OrderOffset, len(orders))
 	}
 
-	// get latest entry
-	latest := orders[cli.OrderOffset]
-	if cli.Verbose {
-		log.Info("Got orders", "latest", latest.Name)
+	return orders[r.OrderOffset:min(r.OrderOffset+count, len(orders))], nil
+}
+
+// writeSlips writes the slip for the first order (a slip for each of its destinations with
+// --per-destination split), or for all of them with --combine, to the output file or to STDOUT if it's "-"
+func (r *RenderCmd) writeSlips(orders []goshopify.Order, cfg slip.Config) (err error) {
+	if !r.combine() {
+		orders = firstOrder(orders)
+	}
+
+	start := time.Now()
+	defer func() {
+		metrics.observeRender(len(orders), time.Since(start), err)
+	}()
+
+	if r.OutputDir != "" {
+		if err := r.writeOrderFiles(orders, cfg); err != nil {
+			return err
+		}
+	}
+	if r.Zip != "" {
+		return r.writeZip(orders, cfg)
+	}
+
+	if r.OutFilename == "-" {
+		return r.renderSlips(os.Stdout, orders, cfg)
 	}
 
-	p.SetXY(p.MarginLeft(), float64(cfg.Config.Logo.VerticalSpace))
-	x := p.GetX()
-	y := p.GetY()
-	err = p.Image(cfg.Config.Logo.Filename, x, y, nil)
+	// without --combine, each copy gets a file of its own
+	if !r.combine() && cfg.Copies.Total > 1 {
+		for i, fn := range r.outFilenames() {
+			cfg.Copies.Number = i + 1
+			if err := r.writeFile(fn, orders, cfg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return r.writeFile(r.OutFilename, orders, cfg)
+}
+
+// firstOrder returns the slips of the first order: just the one, or one per destination
+func firstOrder(orders []goshopify.Order) []goshopify.Order {
+	n := 1
+	for n < len(orders) && orders[n].Id == orders[0].Id {
+		n++
+	}
+	return orders[:n]
+}
+
+// writeFile renders the slips for the orders into the file
+func (r *RenderCmd) writeFile(fn string, orders []goshopify.Order, cfg slip.Config) error {
+	f, err := os.Create(fn)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	if err := r.renderSlips(f, orders, cfg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
 
-	p.SetXY(p.MarginLeft(), float64(cfg.Config.Text.VerticalSpace))
-	p.writeLine("Order " + latest.Name)
-	p.writeLine(latest.CreatedAt.Format("Jan 2, 2006") + "\n\n")
+// writeOrderFiles writes a PDF for each order to the --output-dir, along with the --outfile.
+// With --resume each order is recorded once its last file is written (it has one per destination
+// with --per-destination split), so a run that stops partway doesn't render it again.
+func (r *RenderCmd) writeOrderFiles(orders []goshopify.Order, cfg slip.Config) error {
+	if err := os.MkdirAll(r.OutputDir, 0o755); err != nil {
+		return err
+	}
+	written := 0
+	return eachOrderSlip(orders, cfg, func(order goshopify.Order, cfg slip.Config) error {
+		fn, err := outfileName(r.OutputDir, orderFileTemplate, order)
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(fn)
+		if err != nil {
+			return err
+		}
+		if err := slip.RenderSlips([]goshopify.Order{order}, cfg, f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
 
-	p.changeFontStyle(Bold)
-	p.writeLine("SHIP TO\n")
+		written++
+		if r.Resume && (written == len(orders) || orders[written].Id != order.Id) {
+			if err := r.resume.record([]goshopify.Order{order}); err != nil {
+				return fmt.Errorf("failed to record %s for --resume: %w", order.Name, err)
+			}
+		}
+		return nil
+	})
+}
 
-	p.changeFontStyle(Regular)
-	p.writeLine(latest.ShippingAddress.FirstName + " " + latest.ShippingAddress.LastName)
-	p.writeLine(latest.ShippingAddress.Address1)
-	if latest.ShippingAddress.Address2 != "" {
-		p.writeLine(latest.ShippingAddress.Address2)
+// eachOrderSlip calls fn with each order and the config to render a PDF of its own with. That's the
+// slip number and copies it gets in a combined PDF, so its pages are the same as that order's pages there.
+func eachOrderSlip(orders []goshopify.Order, cfg slip.Config, fn func(goshopify.Order, slip.Config) error) error {
+	first := max(cfg.Batch.Number, 1)
+	for i, order := range orders {
+		cfg.Batch.Number = first + i
+		if err := fn(order, cfg); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	citystate := strings.Builder{}
-	citystate.WriteString(latest.ShippingAddress.City)
-	citystate.WriteString(" ")
-	citystate.WriteString(latest.ShippingAddress.ProvinceCode)
-	citystate.WriteString(" ")
-	citystate.WriteString(latest.ShippingAddress.Zip)
-	citystate.WriteString("\n")
-	p.writeLine(citystate.String())
-	p.writeLine(latest.ShippingAddress.Country + "\n\n")
+// outFilenames returns the files writeSlips writes: the --outfile, or one per copy
+// with the copy number before the extension, like packingslip-2.pdf
+func (r *RenderCmd) outFilenames() []string {
+	if r.combine() || r.Copies <= 1 || r.OutFilename == "-" {
+		return []string{r.OutFilename}
+	}
+	ext := filepath.Ext(r.OutFilename)
+	base := strings.TrimSuffix(r.OutFilename, ext)
+	filenames := make([]string, r.Copies)
+	for i := range filenames {
+		filenames[i] = fmt.Sprintf("%s-%d%s", base, i+1, ext)
+	}
+	return filenames
+}
 
-	for _, lineItem := range latest.LineItems {
-		p.changeFontStyle(Regular)
-		p.writeLine(fmt.Sprintf("Qty %d", lineItem.Quantity))
-		p.changeFontStyle(Bold)
-		p.writeLine(lineItem.Name)
-		p.changeFontStyle(Regular)
-		p.writeLine("SKU: " + lineItem.SKU + "\n\n")
+// renderSlips renders the orders in the --format, a page each if there's more than one
+func (r *RenderCmd) renderSlips(w io.Writer, orders []goshopify.Order, cfg slip.Config) error {
+	if r.Format == "csv" {
+		return writeManifest(w, orders, r.CSVColumns)
+	}
+	if r.Format == "text" {
+		return slip.RenderTexts(orders, cfg, w)
+	}
+	if r.Cover {
+		cfg.Cover.Orders = coverOrders(orders)
 	}
+	if len(orders) > 1 || (cfg.Copies.Total > 1 && cfg.Copies.Number == 0) || r.Cover {
+		return slip.RenderSlips(orders, cfg, w)
+	}
+
+	layout, err := slip.RenderSlipLayout(orders[0], cfg, w)
+	if err != nil {
+		return err
+	}
+	if r.LayoutInfo {
+		return writeLayoutInfo(os.Stderr, layout)
+	}
+	return nil
+}
+
+// the layouts parseTimestamp accepts besides RFC 3339, which are all in local time
+var localTimestampLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// parseTimestamp parses a date or date and time. Ones without a time zone are in local time.
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't read %q as a time, use a date like 2024-05-01 or a time like 2024-05-01T15:04:05-07:00", s)
+}
+
+// useQueueOffset makes the --queue-offset the offset used for everything else,
+// since the queue is just a different order of the orders to offset into
+func (r *RenderCmd) useQueueOffset() error {
+	if r.QueueOffset == nil {
+		return nil
+	}
+	if r.OrderOffset != 0 {
+		return fmt.Errorf("--offset and --queue-offset can't be used together")
+	}
+	if r.Draft || r.CustomerEmail != "" || r.searching() || r.FulfillmentOrderID != 0 {
+		return fmt.Errorf("--queue-offset can't be used with --draft, --customer-email, --query, --tag or --fulfillment-order-id")
+	}
+	if *r.QueueOffset < 0 {
+		return fmt.Errorf("--queue-offset can't be negative")
+	}
+	r.OrderOffset = *r.QueueOffset
+	return nil
+}
+
+// useFromOldest makes the --from-oldest the offset used for everything else,
+// like --queue-offset but through all the orders
+func (r *RenderCmd) useFromOldest() error {
+	if r.FromOldest == nil {
+		return nil
+	}
+	if r.OrderOffset != 0 || r.QueueOffset != nil {
+		return fmt.Errorf("--from-oldest can't be used with --offset or --queue-offset")
+	}
+	if r.Draft || r.CustomerEmail != "" || r.searching() || r.FulfillmentOrderID != 0 {
+		return fmt.Errorf("--from-oldest can't be used with --draft, --customer-email, --query, --tag or --fulfillment-order-id")
+	}
+	if *r.FromOldest < 0 {
+		return fmt.Errorf("--from-oldest can't be negative")
+	}
+	r.OrderOffset = *r.FromOldest
+	return nil
+}
+
+// mostRecent reports whether the slip is for the most recent order because no order was picked,
+// with --offset, --queue-offset, --from-oldest, --fulfillment-order-id or the tui, and only one is rendered
+func (r *RenderCmd) mostRecent() bool {
+	return r.OrderOffset == 0 && r.QueueOffset == nil && r.FromOldest == nil && r.FulfillmentOrderID == 0 && r.picked == nil && !r.combine()
+}
+
+// noOrders is the error for a store (or the filters) leaving no orders at all to render
+func (r *RenderCmd) noOrders() error {
+	kind := "orders"
+	if r.Draft {
+		kind = "draft orders"
+	}
+	if r.Status != "any" || r.FulfillmentStatus != "" || !r.updatedAfter.IsZero() || r.QueueOffset != nil {
+		return fmt.Errorf("no %s match the filters", kind)
+	}
+	return fmt.Errorf("no %s were found", kind)
+}
+
+// combine reports whether several orders go into one PDF, which a customer's orders,
+// the orders matching a query or tag and the ones in an orders file always do, or into one CSV manifest
+func (r *RenderCmd) combine() bool {
+	return r.Combine || r.CustomerEmail != "" || r.searching() || r.OrdersFile != "" || r.Format == "csv"
+}
+
+// applyFlags overrides the config with the flags that have a matching config setting
+func (r *RenderCmd) applyFlags(cfg *slip.Config) {
+	if r.ShowBilling {
+		cfg.Billing.Show = true
+	}
+	if r.SortItems != "original" {
+		cfg.Items.Sort = r.SortItems
+	}
+	if r.Fit {
+		cfg.Fit.Enabled = true
+	}
+	if r.ShowVendor {
+		cfg.Items.ShowVendor = true
+	}
+	if r.NetQuantities {
+		cfg.Items.NetQuantities = true
+	}
+	if r.StripEmoji {
+		cfg.Items.Emoji = "strip"
+	}
+	if r.ShowPayment {
+		cfg.Payment.Show = true
+	}
+	if r.ShowSource {
+		cfg.Source.Show = true
+	}
+	if r.ShowRisk {
+		cfg.Risk.Show = true
+	}
+	if r.Table {
+		cfg.Items.Layout = "table"
+	}
+	if r.Copies > 1 {
+		cfg.Copies.Total = r.Copies
+	}
+	if r.PDFMetadata {
+		cfg.PDFMetadata.Enabled = true
+	}
+	if r.Monochrome {
+		cfg.Monochrome.Enabled = true
+	}
+	if r.GroupByVendor {
+		cfg.Items.GroupByVendor = true
+	}
+	if r.Watermark != "" {
+		cfg.Page.Watermark = r.Watermark
+	}
+	if r.Compact {
+		cfg.Compact.Enabled = true
+	}
+	if r.TextWidth != 0 {
+		cfg.Text.Width = r.TextWidth
+	}
+	if r.ShowHash {
+		cfg.Hash.Show = true
+	}
+	// the slip number is the position in the batch, wherever the offset starts
+	cfg.Batch.Total = r.BatchTotal
+	cfg.Batch.Number = r.OrderOffset + 1
+	cfg.Metafields.Keys = append(cfg.Metafields.Keys, r.Metafields...)
+}
 
-	p.writeLine(cfg.Config.Text.Salutation)
-	p.changeFontStyle(Bold)
-	p.writeLine(cfg.Config.Text.Signature)
+// listOrders prints the orders that --offset can select, starting at the given offset
+func (r *RenderCmd) listOrders(cli *CLIFlags) error {
+	secrets, err := loadSecrets(cli.SecretsFilename)
+	if err != nil {
+		return err
+	}
 
-	err = p.WritePdf(cli.OutFilename)
+	client, err := newClient(*secrets)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	// no deadline on the whole list, since newClient times out each request and the retries wait as long as Shopify asks
+	ctx := context.Background()
+
+	count := r.Count
+	if count <= 0 {
+		count = defaultListCount
+	}
+
+	orders, err := r.fetchOrders(ctx, client, r.OrderOffset+count)
+	if err != nil {
+		return err
+	}
+	if r.OrderOffset >= len(orders) {
+		if r.CustomerEmail != "" {
+			fmt.Printf("No orders found for %s\n", r.CustomerEmail)
+			return nil
+		}
+		if r.searching() {
+			return r.noMatches()
+		}
+		fmt.Println("No orders found")
+		return nil
+	}
+
+	orders = orders[r.OrderOffset:min(r.OrderOffset+count, len(orders))]
+	r.warnIncomplete(orders)
+	if r.SkipTest || r.Strict {
+		orders = skipTestOrders(orders)
+		if len(orders) == 0 {
+			return fmt.Errorf("not rendering test orders with --skip-test or --strict")
+		}
+	}
+	return writeOrderList(os.Stdout, orders, r.OrderOffset)
+}
+
+// writeLayoutInfo writes the final cursor position and the extent of each section
+func writeLayoutInfo(w io.Writer, layout *slip.Layout) error {
+	fits := "fits"
+	if layout.Overflowed {
+		fits = "OVERFLOWED"
+	}
+	fmt.Fprintf(w, "final y: %.1f of %.1f (%s, font size %.1f)\n", layout.FinalY, layout.PageHeight, fits, layout.FontSize)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SECTION\tSTART\tEND")
+	for _, s := range layout.Sections {
+		fmt.Fprintf(tw, "%s\t%.1f\t%.1f\n", s.Name, s.StartY, s.EndY)
+	}
+	return tw.Flush()
+}
+
+// openFile opens a file with the OS default viewer.
+// It only warns when there's no display to open it on.
+func openFile(fn string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", fn)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", fn)
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			warn("No display available, skipping preview", "file", fn)
+			return nil
+		}
+		cmd = exec.Command("xdg-open", fn)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", fn, err)
 	}
+	return nil
 }
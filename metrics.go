@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// the kinds of error --metrics-file counts, in the order they're written
+var errorTypes = []string{"config", "api", "render"}
+
+// the upper bounds of the render duration histogram, in seconds
+var renderBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// runMetrics is what --metrics-file reports about a run
+type runMetrics struct {
+	// stage is the kind of error a failure right now would be counted as
+	stage     string
+	rendered  int
+	errors    map[string]int
+	durations []time.Duration
+}
+
+var metrics = runMetrics{stage: "config", errors: map[string]int{}}
+
+// observeRender records a render of the slips for some orders, and how long it took
+func (m *runMetrics) observeRender(orders int, d time.Duration, err error) {
+	m.durations = append(m.durations, d)
+	if err == nil {
+		m.rendered += orders
+	}
+}
+
+// fail counts an error as the kind of the stage the run is in
+func (m *runMetrics) fail(stage string) {
+	m.errors[stage]++
+}
+
+// writeMetricsFile writes the metrics in the Prometheus text format, for node_exporter's textfile collector.
+// It writes a temporary file and renames it, so the collector never reads half a file.
+func writeMetricsFile(fn string, m runMetrics) error {
+	var b strings.Builder
+	fmt.Fprintln(&b, "# HELP packingslipper_slips_rendered_total Slips rendered.")
+	fmt.Fprintln(&b, "# TYPE packingslipper_slips_rendered_total counter")
+	fmt.Fprintf(&b, "packingslipper_slips_rendered_total %d\n", m.rendered)
+
+	fmt.Fprintln(&b, "# HELP packingslipper_errors_total Errors, by type.")
+	fmt.Fprintln(&b, "# TYPE packingslipper_errors_total counter")
+	for _, t := range errorTypes {
+		fmt.Fprintf(&b, "packingslipper_errors_total{type=%q} %d\n", t, m.errors[t])
+	}
+
+	fmt.Fprintln(&b, "# HELP packingslipper_render_duration_seconds Time taken to render and write the slips.")
+	fmt.Fprintln(&b, "# TYPE packingslipper_render_duration_seconds histogram")
+	var sum float64
+	for _, d := range m.durations {
+		sum += d.Seconds()
+	}
+	for _, le := range renderBuckets {
+		n := 0
+		for _, d := range m.durations {
+			if d.Seconds() <= le {
+				n++
+			}
+		}
+		fmt.Fprintf(&b, "packingslipper_render_duration_seconds_bucket{le=\"%g\"} %d\n", le, n)
+	}
+	fmt.Fprintf(&b, "packingslipper_render_duration_seconds_bucket{le=\"+Inf\"} %d\n", len(m.durations))
+	fmt.Fprintf(&b, "packingslipper_render_duration_seconds_sum %g\n", sum)
+	fmt.Fprintf(&b, "packingslipper_render_duration_seconds_count %d\n", len(m.durations))
+
+	fmt.Fprintln(&b, "# HELP packingslipper_last_run_timestamp_seconds When the run finished.")
+	fmt.Fprintln(&b, "# TYPE packingslipper_last_run_timestamp_seconds gauge")
+	fmt.Fprintf(&b, "packingslipper_last_run_timestamp_seconds %d\n", time.Now().Unix())
+
+	tmp, err := os.CreateTemp(filepath.Dir(fn), ".metrics-*")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	// CreateTemp makes the file private, but the collector may run as another user
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return os.Rename(tmp.Name(), fn)
+}
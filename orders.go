@@ -0,0 +1,489 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/charmbracelet/log"
+	"github.com/rahji/packingslipper/slip"
+)
+
+// the most orders Shopify will return in one page
+const maxPageSize = 250
+
+// fetchOrders gets at least limit of the recent orders (if there are that many), most recent first,
+// or the oldest ones first with --from-oldest, or the oldest unfulfilled orders first with --queue-offset. With --updated-after, only the orders
+// changed since then are included.
+// Draft orders are converted so they can be rendered like regular orders.
+func (r *RenderCmd) fetchOrders(ctx context.Context, client *goshopify.Client, limit int) ([]goshopify.Order, error) {
+	if r.Draft {
+		if r.CustomerEmail != "" || r.searching() {
+			return nil, fmt.Errorf("--customer-email, --query and --tag can't be used with --draft")
+		}
+		return fetchDraftOrders(ctx, client, r.updatedAfter)
+	}
+	if r.CustomerEmail != "" {
+		return r.fetchCustomerOrders(ctx, client, limit)
+	}
+	if r.searching() {
+		return r.fetchQueryOrders(ctx, client, limit)
+	}
+
+	listOptions := goshopify.OrderListOptions{
+		ListOptions:       goshopify.ListOptions{Limit: min(limit, maxPageSize), UpdatedAtMin: r.updatedAfter},
+		Status:            goshopify.OrderStatus(r.Status),
+		FulfillmentStatus: goshopify.OrderFulfillmentStatus(r.FulfillmentStatus),
+	}
+	if r.FromOldest != nil {
+		listOptions.Order = "created_at asc"
+	}
+	// the fulfillment queue is worked through oldest first,
+	// and only has the orders still to pack unless --fulfillment-status says otherwise
+	if r.QueueOffset != nil {
+		listOptions.Order = "created_at asc"
+		if listOptions.FulfillmentStatus == "" {
+			listOptions.FulfillmentStatus = goshopify.OrderFulfillmentStatus("unfulfilled")
+		}
+	}
+
+	var options interface{} = listOptions
+
+	var orders []goshopify.Order
+	for {
+		page, pagination, err := client.Order.ListWithPagination(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, page...)
+
+		if len(orders) >= limit || pagination == nil || pagination.NextPageOptions == nil {
+			return orders, nil
+		}
+		// the page_info for the next page already carries the filters
+		options = pagination.NextPageOptions
+	}
+}
+
+// fetchCustomerOrders gets the recent orders of the customers with the --customer-email address, most recent first.
+// There can be more than one customer with the address, e.g. after a guest checkout.
+func (r *RenderCmd) fetchCustomerOrders(ctx context.Context, client *goshopify.Client, limit int) ([]goshopify.Order, error) {
+	customers, err := client.Customer.Search(ctx, goshopify.CustomerSearchOptions{Query: "email:" + r.CustomerEmail})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customer %s: %w", r.CustomerEmail, err)
+	}
+
+	options := goshopify.OrderListOptions{
+		ListOptions:       goshopify.ListOptions{Limit: min(limit, maxPageSize), UpdatedAtMin: r.updatedAfter},
+		Status:            goshopify.OrderStatus(r.Status),
+		FulfillmentStatus: goshopify.OrderFulfillmentStatus(r.FulfillmentStatus),
+	}
+
+	var orders []goshopify.Order
+	for _, c := range customers {
+		// the search matches loosely, so only keep the exact address
+		if !strings.EqualFold(c.Email, r.CustomerEmail) {
+			continue
+		}
+		page, err := client.Customer.ListOrders(ctx, c.Id, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get orders for %s: %w", r.CustomerEmail, err)
+		}
+		orders = append(orders, page...)
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(*orders[j].CreatedAt)
+	})
+	return orders, nil
+}
+
+// ordersQuery finds the orders matching a search query, most recent first.
+// Only the REST orders have the fields the slip uses, so it just gets their IDs.
+const ordersQuery = `query($query: String!, $first: Int!, $after: String) {
+  orders(first: $first, after: $after, query: $query, sortKey: CREATED_AT, reverse: true) {
+    nodes { legacyResourceId }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+// fetchQueryOrders gets at least limit of the most recent orders matching the --query and --tag (if there are that many),
+// along with the --status, --fulfillment-status and --updated-after filters.
+// The REST API can't search orders, so they're found with the GraphQL API and then fetched by their IDs.
+func (r *RenderCmd) fetchQueryOrders(ctx context.Context, client *goshopify.Client, limit int) ([]goshopify.Order, error) {
+	ids, err := searchOrderIDs(ctx, client, r.searchQuery(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return fetchOrdersByID(ctx, client, ids)
+}
+
+// fetchOrdersByID gets the orders with the IDs, in the same order, a page of them per request
+// rather than a request each. IDs that aren't orders are left out.
+func fetchOrdersByID(ctx context.Context, client *goshopify.Client, ids []uint64) ([]goshopify.Order, error) {
+	byID := map[uint64]goshopify.Order{}
+	for start := 0; start < len(ids); start += maxPageSize {
+		page := ids[start:min(start+maxPageSize, len(ids))]
+		options := goshopify.OrderListOptions{
+			ListOptions: goshopify.ListOptions{Ids: page, Limit: maxPageSize},
+			Status:      goshopify.OrderStatus("any"),
+		}
+		orders, err := client.Order.List(ctx, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get orders: %w", err)
+		}
+		for _, o := range orders {
+			byID[o.Id] = o
+		}
+	}
+
+	orders := make([]goshopify.Order, 0, len(ids))
+	for _, id := range ids {
+		if o, ok := byID[id]; ok {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+// searchOrderIDs returns the IDs of up to limit of the most recent orders matching the search query
+func searchOrderIDs(ctx context.Context, client *goshopify.Client, query string, limit int) ([]uint64, error) {
+	var ids []uint64
+	var after *string
+	for len(ids) < limit {
+		var resp struct {
+			Orders struct {
+				Nodes []struct {
+					LegacyResourceId string `json:"legacyResourceId"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"orders"`
+		}
+		vars := map[string]interface{}{"query": query, "first": min(limit-len(ids), maxPageSize), "after": after}
+		if err := client.GraphQL.Query(ctx, ordersQuery, vars, &resp); err != nil {
+			return nil, fmt.Errorf("failed to search orders for %q: %w", query, err)
+		}
+		for _, node := range resp.Orders.Nodes {
+			id, err := strconv.ParseUint(node.LegacyResourceId, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected order ID %q in the search results", node.LegacyResourceId)
+			}
+			ids = append(ids, id)
+		}
+		if !resp.Orders.PageInfo.HasNextPage {
+			break
+		}
+		after = &resp.Orders.PageInfo.EndCursor
+	}
+	return ids, nil
+}
+
+// searching reports whether the orders are found with a search, for --query or --tag
+func (r *RenderCmd) searching() bool {
+	return r.Query != "" || r.Tag != ""
+}
+
+// noMatches is the error for a search that found no orders
+func (r *RenderCmd) noMatches() error {
+	switch {
+	case r.Query == "":
+		return fmt.Errorf("no orders are tagged %q", r.Tag)
+	case r.Tag == "":
+		return fmt.Errorf("no orders match the --query %q", r.Query)
+	}
+	return fmt.Errorf("no orders tagged %q match the --query %q", r.Tag, r.Query)
+}
+
+// searchQuery returns the --query and --tag, with the other order filters added in the search syntax
+func (r *RenderCmd) searchQuery() string {
+	var terms []string
+	if r.Query != "" {
+		terms = append(terms, "("+r.Query+")")
+	}
+	if r.Tag != "" {
+		// quoted, so tags with spaces or colons are matched whole
+		terms = append(terms, "tag:"+strconv.Quote(r.Tag))
+	}
+	if r.Status != "" && r.Status != "any" {
+		terms = append(terms, "status:"+r.Status)
+	}
+	if r.FulfillmentStatus != "" && r.FulfillmentStatus != "any" {
+		terms = append(terms, "fulfillment_status:"+r.FulfillmentStatus)
+	}
+	if !r.updatedAfter.IsZero() {
+		terms = append(terms, fmt.Sprintf("updated_at:>='%s'", r.updatedAfter.Format(time.RFC3339)))
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// warnIncomplete warns about orders that came back with neither line items nor an address.
+// Shopify leaves out fields the token's scopes don't cover rather than failing the request,
+// so an order like that usually means a missing scope, not an empty order.
+func (r *RenderCmd) warnIncomplete(orders []goshopify.Order) {
+	for _, o := range orders {
+		if len(o.LineItems) > 0 || o.ShippingAddress != nil || o.BillingAddress != nil {
+			continue
+		}
+
+		scope := "read_orders"
+		if r.Draft {
+			scope = "read_draft_orders"
+		}
+		// without customer data access the customer is missing too
+		if o.Customer == nil {
+			scope += " and read_customers (with protected customer data access)"
+		}
+		warn("Order has no line items or addresses, the API token may be missing scopes", "order", o.Name, "scopes", scope)
+	}
+}
+
+// skipTestOrders returns the orders that aren't test orders, warning about each one it leaves out
+func skipTestOrders(orders []goshopify.Order) []goshopify.Order {
+	var kept []goshopify.Order
+	for _, o := range orders {
+		if slip.IsTestOrder(o) {
+			warn("Skipping test order", "order", o.Name)
+			continue
+		}
+		kept = append(kept, o)
+	}
+	return kept
+}
+
+// fetchTransactions gets the transactions of each order, for the payment section.
+// Without access to them (the token lacks a scope), or for draft orders, which haven't been paid,
+// it warns and the slips go without a payment section rather than failing.
+func (r *RenderCmd) fetchTransactions(ctx context.Context, client *goshopify.Client, orders []goshopify.Order) error {
+	if r.Draft {
+		warn("Draft orders have no payments, leaving the payment out")
+		return nil
+	}
+	for i := range orders {
+		transactions, err := client.Transaction.List(ctx, orders[i].Id, nil)
+		if missingScope(err) {
+			warn("Can't read the order transactions, the API token may be missing scopes, leaving the payment out", "scopes", "read_orders", "err", err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get transactions for %s: %w", orders[i].Name, err)
+		}
+		orders[i].Transactions = transactions
+	}
+	return nil
+}
+
+// fetchRisks gets the highest fraud risk recommendation for each order, by order ID.
+// Like the transactions, it warns and leaves the risk banner out if the token can't read them.
+func (r *RenderCmd) fetchRisks(ctx context.Context, client *goshopify.Client, orders []goshopify.Order) (map[uint64]goshopify.OrderRiskRecommendation, error) {
+	if r.Draft {
+		warn("Draft orders have no fraud risk, leaving the risk out")
+		return nil, nil
+	}
+	recommendations := map[uint64]goshopify.OrderRiskRecommendation{}
+	for _, o := range orders {
+		risks, err := client.OrderRisk.List(ctx, o.Id, nil)
+		if missingScope(err) {
+			warn("Can't read the order risks, the API token may be missing scopes, leaving the risk out", "scopes", "read_orders", "err", err)
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get risks for %s: %w", o.Name, err)
+		}
+		recommendations[o.Id] = slip.HighestRisk(risks)
+	}
+	return recommendations, nil
+}
+
+// missingScope reports whether the error is Shopify refusing a request the token doesn't have the scope for
+func missingScope(err error) bool {
+	var respErr goshopify.ResponseError
+	return errors.As(err, &respErr) && (respErr.Status == http.StatusForbidden || respErr.Status == http.StatusUnauthorized)
+}
+
+// fetchFulfillmentOrder gets a fulfillment order and returns its order with only the line items
+// (and quantities) assigned to it, along with the address of the location it ships from
+func fetchFulfillmentOrder(ctx context.Context, client *goshopify.Client, id uint64) (goshopify.Order, *goshopify.Address, error) {
+	fo, err := client.FulfillmentOrder.Get(ctx, id, nil)
+	if err != nil {
+		return goshopify.Order{}, nil, fmt.Errorf("failed to get fulfillment order %d: %w", id, err)
+	}
+
+	order, err := client.Order.Get(ctx, fo.OrderId, nil)
+	if err != nil {
+		return goshopify.Order{}, nil, fmt.Errorf("failed to get order %d for fulfillment order %d: %w", fo.OrderId, id, err)
+	}
+
+	return fulfillmentOrderItems(*order, []goshopify.FulfillmentOrder{*fo}), locationAddress(fo.AssignedLocation), nil
+}
+
+// fulfillmentOrderItems returns the order with only the line items (and quantities) assigned to the fulfillment orders
+func fulfillmentOrderItems(order goshopify.Order, fos []goshopify.FulfillmentOrder) goshopify.Order {
+	quantities := map[uint64]int{}
+	for _, fo := range fos {
+		for _, foItem := range fo.LineItems {
+			quantities[foItem.LineItemId] += int(foItem.Quantity)
+		}
+	}
+	var lineItems []goshopify.LineItem
+	for _, lineItem := range order.LineItems {
+		if qty, ok := quantities[lineItem.Id]; ok {
+			lineItem.Quantity = qty
+			lineItems = append(lineItems, lineItem)
+		}
+	}
+	order.LineItems = lineItems
+	return order
+}
+
+// locationAddress returns the address of a fulfillment order's location, for the FROM block
+func locationAddress(loc goshopify.FulfillmentOrderAssignedLocation) *goshopify.Address {
+	return &goshopify.Address{
+		FirstName:    loc.Name,
+		Address1:     loc.Address1,
+		Address2:     loc.Address2,
+		City:         loc.City,
+		ProvinceCode: loc.Province,
+		Zip:          loc.Zip,
+		Country:      loc.CountryCode,
+	}
+}
+
+// fetchLocationItems keeps the line items of each order that are assigned to the --location-id, using the
+// open fulfillment orders of that location, and leaves out the orders with nothing to ship from there.
+// It returns the location's address for the FROM block.
+func (r *RenderCmd) fetchLocationItems(ctx context.Context, client *goshopify.Client, orders []goshopify.Order) ([]goshopify.Order, *goshopify.Address, error) {
+	var kept []goshopify.Order
+	var from *goshopify.Address
+	for _, o := range orders {
+		fos, err := client.FulfillmentOrder.List(ctx, o.Id, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the fulfillment orders of %s: %w", o.Name, err)
+		}
+		var atLocation []goshopify.FulfillmentOrder
+		for _, fo := range fos {
+			// closed and cancelled fulfillment orders have nothing left to ship
+			if fo.AssignedLocationId == r.LocationID && fo.Status != "closed" && fo.Status != "cancelled" {
+				atLocation = append(atLocation, fo)
+			}
+		}
+		if len(atLocation) == 0 {
+			log.Info("Leaving out an order with nothing to ship from the location", "order", o.Name, "location", r.LocationID)
+			continue
+		}
+		if from == nil {
+			from = locationAddress(atLocation[0].AssignedLocation)
+		}
+		kept = append(kept, fulfillmentOrderItems(o, atLocation))
+	}
+	if len(kept) == 0 {
+		return nil, nil, fmt.Errorf("nothing to ship from location %d in the orders", r.LocationID)
+	}
+	return kept, from, nil
+}
+
+// fetchMetafields fills in the metafields of each order
+func (r *RenderCmd) fetchMetafields(ctx context.Context, client *goshopify.Client, orders []goshopify.Order) error {
+	options := goshopify.ListOptions{Limit: maxPageSize}
+	for i := range orders {
+		var metafields []goshopify.Metafield
+		var err error
+		if r.Draft {
+			metafields, err = client.DraftOrder.ListMetafields(ctx, orders[i].Id, options)
+		} else {
+			metafields, err = client.Order.ListMetafields(ctx, orders[i].Id, options)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get metafields for %s: %w", orders[i].Name, err)
+		}
+		orders[i].Metafields = metafields
+	}
+	return nil
+}
+
+// fetchDraftOrders gets the draft orders, most recent first,
+// only the ones updated since updatedAfter if it isn't zero.
+// They come back oldest first, so they're all fetched, a page at a time after the last ID
+// of the one before, and then sorted. It's the newest ones the limit is for.
+func fetchDraftOrders(ctx context.Context, client *goshopify.Client, updatedAfter time.Time) ([]goshopify.Order, error) {
+	options := goshopify.DraftOrderListOptions{Limit: maxPageSize}
+	if !updatedAfter.IsZero() {
+		options.UpdatedAtMin = &updatedAfter
+	}
+
+	var orders []goshopify.Order
+	for {
+		drafts, err := client.DraftOrder.List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range drafts {
+			orders = append(orders, orderFromDraft(d))
+		}
+		if len(drafts) < maxPageSize {
+			break
+		}
+		options.SinceId = drafts[len(drafts)-1].Id
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(*orders[j].CreatedAt)
+	})
+	return orders, nil
+}
+
+// orderFromDraft copies the fields of a draft order that the slip uses into a regular order
+func orderFromDraft(d goshopify.DraftOrder) goshopify.Order {
+	return goshopify.Order{
+		Id:              d.Id,
+		Name:            d.Name,
+		Email:           d.Email,
+		CreatedAt:       d.CreatedAt,
+		UpdatedAt:       d.UpdatedAt,
+		Customer:        d.Customer,
+		BillingAddress:  d.BillingAddress,
+		ShippingAddress: d.ShippingAddress,
+		Currency:        d.Currency,
+		Note:            d.Note,
+		NoteAttributes:  d.NoteAttributes,
+		LineItems:       d.LineItems,
+		Tags:            d.Tags,
+	}
+}
+
+// customerName returns the name to show for the order's customer,
+// preferring the shipping address since that's who the slip is for
+func customerName(o goshopify.Order) string {
+	switch {
+	case o.ShippingAddress != nil:
+		return strings.TrimSpace(o.ShippingAddress.FirstName + " " + o.ShippingAddress.LastName)
+	case o.Customer != nil:
+		return strings.TrimSpace(o.Customer.FirstName + " " + o.Customer.LastName)
+	default:
+		return o.Email
+	}
+}
+
+// writeOrderList writes a table of orders along with the --offset that selects each one
+func writeOrderList(w io.Writer, orders []goshopify.Order, firstOffset int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OFFSET\tORDER\tDATE\tCUSTOMER")
+	for i, o := range orders {
+		date := ""
+		if o.CreatedAt != nil {
+			date = o.CreatedAt.Format("Jan 2, 2006")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", firstOffset+i, o.Name, date, customerName(o))
+	}
+	return tw.Flush()
+}
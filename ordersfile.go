@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// order IDs have more digits than this, so a shorter number is an order number
+const maxOrderNumberDigits = 9
+
+// useOrdersFile checks the --orders-file isn't used with anything else that picks the orders
+func (r *RenderCmd) useOrdersFile() error {
+	if r.OrdersFile == "" {
+		return nil
+	}
+	if r.OrderOffset != 0 || r.QueueOffset != nil || r.FromOldest != nil || r.FulfillmentOrderID != 0 {
+		return fmt.Errorf("--orders-file can't be used with --offset, --queue-offset, --from-oldest or --fulfillment-order-id")
+	}
+	if r.Draft || r.CustomerEmail != "" || r.searching() {
+		return fmt.Errorf("--orders-file can't be used with --draft, --customer-email, --query or --tag")
+	}
+	if r.Status != "any" || r.FulfillmentStatus != "" || r.UpdatedAfter != "" {
+		return fmt.Errorf("--status, --fulfillment-status and --updated-after don't apply to --orders-file")
+	}
+	return nil
+}
+
+// fetchOrdersFile gets the orders listed in the --orders-file, warning about the ones that weren't found
+// once it has looked for all of them. It's an error if none of them were.
+func (r *RenderCmd) fetchOrdersFile(ctx context.Context, client *goshopify.Client) ([]goshopify.Order, error) {
+	listed, err := readOrdersFile(r.OrdersFile)
+	if err != nil {
+		return nil, err
+	}
+	orders, missing, err := fetchListedOrders(ctx, client, listed)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("none of the orders in %s were found", r.OrdersFile)
+	}
+	if len(missing) > 0 {
+		warn("Some orders in the orders file weren't found", "file", r.OrdersFile, "orders", strings.Join(missing, ", "))
+	}
+	return orders, nil
+}
+
+// readOrdersFile returns the orders listed in the --orders-file, one order number (like #1001 or 1001)
+// or ID per line, leaving out blank lines, comments from a "# " to the end of the line, and repeats
+func readOrdersFile(fn string) ([]string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var listed []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.ReplaceAll(scanner.Text(), "\t", " ")
+		if line = strings.TrimSpace(line); line == "#" {
+			continue
+		}
+		if i := strings.Index(line, "# "); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		if strings.ContainsRune(line, ' ') {
+			return nil, fmt.Errorf("%s line %d: expected one order per line, got %q", fn, n, line)
+		}
+		if !seen[line] {
+			seen[line] = true
+			listed = append(listed, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fn, err)
+	}
+	if len(listed) == 0 {
+		return nil, fmt.Errorf("%s doesn't list any orders", fn)
+	}
+	return listed, nil
+}
+
+// fetchListedOrders gets the orders in the --orders-file, in the file's order. The ones that
+// can't be found are returned as missing rather than stopping the others. The IDs are fetched
+// a page at a time, and the order numbers are looked up a few dozen to a search, rather than
+// a request or more for each line.
+func fetchListedOrders(ctx context.Context, client *goshopify.Client, listed []string) ([]goshopify.Order, []string, error) {
+	var ids []uint64
+	var numbers []string
+	for _, l := range listed {
+		if id, ok := listedOrderID(l); ok {
+			ids = append(ids, id)
+		} else {
+			numbers = append(numbers, strings.TrimPrefix(l, "#"))
+		}
+	}
+	found, err := fetchOrdersByID(ctx, client, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := map[uint64]goshopify.Order{}
+	for _, o := range found {
+		byID[o.Id] = o
+	}
+	byNumber, err := fetchOrdersByNumber(ctx, client, numbers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var orders []goshopify.Order
+	var missing []string
+	seen := map[uint64]bool{}
+	for _, l := range listed {
+		var order goshopify.Order
+		var ok bool
+		if id, isID := listedOrderID(l); isID {
+			order, ok = byID[id]
+		} else {
+			order, ok = byNumber[strings.ToLower(strings.TrimPrefix(l, "#"))]
+		}
+		if !ok {
+			missing = append(missing, l)
+			continue
+		}
+		// #1001 and 1001 are the same order
+		if !seen[order.Id] {
+			seen[order.Id] = true
+			orders = append(orders, order)
+		}
+	}
+	return orders, missing, nil
+}
+
+// listedOrderID returns the order ID a line of the --orders-file is, if it's an ID rather than an order number
+func listedOrderID(listed string) (uint64, bool) {
+	if len(listed) <= maxOrderNumberDigits {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(listed, 10, 64)
+	return id, err == nil
+}
+
+// how many order numbers are looked up in one search, which keeps the query short
+const numbersPerSearch = 50
+
+// fetchOrdersByNumber gets the orders with the numbers (like 1001, without the #), keyed by
+// the number in lowercase. Numbers that no order has are left out.
+func fetchOrdersByNumber(ctx context.Context, client *goshopify.Client, numbers []string) (map[string]goshopify.Order, error) {
+	wanted := map[string]bool{}
+	var ids []uint64
+	for start := 0; start < len(numbers); start += numbersPerSearch {
+		chunk := numbers[start:min(start+numbersPerSearch, len(numbers))]
+		terms := make([]string, len(chunk))
+		for i, n := range chunk {
+			terms[i] = "name:" + strconv.Quote(n)
+			wanted[strings.ToLower(n)] = true
+		}
+		// the search matches more than the exact name, so it gets a few orders for each number
+		// and the ones that aren't exact are left out below
+		found, err := searchOrderIDs(ctx, client, strings.Join(terms, " OR "), 5*len(chunk))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, found...)
+	}
+
+	orders, err := fetchOrdersByID(ctx, client, ids)
+	if err != nil {
+		return nil, err
+	}
+	byNumber := map[string]goshopify.Order{}
+	for _, o := range orders {
+		if number := strings.ToLower(strings.TrimPrefix(o.Name, "#")); wanted[number] {
+			byNumber[number] = o
+		}
+	}
+	return byNumber, nil
+}
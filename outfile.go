@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// outfileData is what an --outfile template is executed with: the order's fields, plus its date
+type outfileData struct {
+	goshopify.Order
+	Date string
+}
+
+// unsafeFilenameChars are replaced in a templated filename, since they're a directory separator
+// or aren't allowed in filenames somewhere
+var unsafeFilenameChars = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "-", "<", "-", ">", "-", "|", "-")
+
+// parseOutfileTemplate returns the template in the base name of the --outfile,
+// or nil if it doesn't use any template actions
+func parseOutfileTemplate(fn string) (*template.Template, error) {
+	base := filepath.Base(fn)
+	if !strings.Contains(base, "{{") {
+		return nil, nil
+	}
+	t, err := template.New("--outfile").Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --outfile template: %w", err)
+	}
+	return t, nil
+}
+
+// orderFileTemplate names the files written to the --output-dir, like 1001.pdf for order #1001
+var orderFileTemplate = template.Must(template.New("--output-dir").Funcs(template.FuncMap{"trimPrefix": strings.TrimPrefix}).Parse(`{{trimPrefix .Name "#"}}.pdf`))
+
+// outfileName executes the template with the order and puts the result in the directory.
+// The result is made safe for a filename, so an order field can't reach into another directory.
+func outfileName(dir string, t *template.Template, order goshopify.Order) (string, error) {
+	data := outfileData{Order: order}
+	if order.CreatedAt != nil {
+		data.Date = order.CreatedAt.Format("2006-01-02")
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", t.Name(), err)
+	}
+
+	base := strings.Map(func(r rune) rune {
+		if r < ' ' {
+			return -1
+		}
+		return r
+	}, unsafeFilenameChars.Replace(buf.String()))
+	base = strings.TrimSpace(base)
+	if base == "" || base == "." || base == ".." {
+		return "", fmt.Errorf("%s template made an unusable filename %q", t.Name(), buf.String())
+	}
+	return filepath.Join(dir, base), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rahji/packingslipper/slip"
+	"gopkg.in/yaml.v2"
+)
+
+// applyPreset overlays the --preset on the config, the same way a later config file overrides
+// only the settings it has. Every preset is checked first, so a typo in one is found even when
+// it isn't the one being used. They're checked on an empty config rather than a copy of this one,
+// which would share its maps, so an unused preset's section-conditions or labels don't leak in.
+func (r *RenderCmd) applyPreset(cfg *slip.Config) error {
+	for _, name := range presetNames(cfg) {
+		if err := overlayPreset(&slip.Config{}, name, cfg.Presets[name]); err != nil {
+			return err
+		}
+	}
+	if r.Preset == "" {
+		return nil
+	}
+	if _, ok := cfg.Presets[r.Preset]; !ok {
+		names := presetNames(cfg)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown --preset %q, the config has no presets", r.Preset)
+		}
+		return fmt.Errorf("unknown --preset %q (use %s)", r.Preset, strings.Join(names, ", "))
+	}
+	return overlayPreset(cfg, r.Preset, cfg.Presets[r.Preset])
+}
+
+// overlayPreset unmarshals the named preset over the config
+func overlayPreset(cfg *slip.Config, name string, preset interface{}) error {
+	if settings, ok := preset.(map[interface{}]interface{}); ok {
+		if _, ok := settings["presets"]; ok {
+			return fmt.Errorf("preset %s: presets can't have presets of their own", name)
+		}
+	}
+	data, err := yaml.Marshal(preset)
+	if err != nil {
+		return fmt.Errorf("preset %s: %w", name, err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse preset %s: %w", name, tidyYAMLError(err))
+	}
+	return nil
+}
+
+// presetNames returns the names of the presets in the config, sorted
+func presetNames(cfg *slip.Config) []string {
+	names := make([]string, 0, len(cfg.Presets))
+	for name := range cfg.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
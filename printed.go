@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/charmbracelet/log"
+)
+
+// printedOrders is the --skip-duplicates record of when each of a shop's orders was last rendered,
+// a line per order like "5551234567 1714571045 #1001" in a file named after the shop.
+// It's locked from when it's read until the run is done, so a run that overlaps another (two cron
+// jobs, or a webhook that fires twice) waits for it and then sees the orders it rendered.
+type printedOrders struct {
+	path    string
+	lock    *os.File
+	printed map[uint64]time.Time
+	names   map[uint64]string
+}
+
+// printedPath returns the file the shop's rendered orders are recorded in, next to the --resume state
+func printedPath(shop string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(shop))
+	return filepath.Join(dir, "packingslipper", "printed", hex.EncodeToString(sum[:8])), nil
+}
+
+// loadPrintedOrders locks the shop's record, waiting for another run that has it, and reads it.
+// It's empty if nothing was recorded yet.
+func loadPrintedOrders(shop string) (*printedOrders, error) {
+	path, err := printedPath(shop)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	// the lock is a file of its own, since the record is replaced rather than written over
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", lock.Name(), err)
+	}
+
+	p := &printedOrders{path: path, lock: lock, printed: map[uint64]time.Time{}, names: map[uint64]string{}}
+	err = readOrderRecords(path, func(id uint64, rest string) {
+		at, name, _ := strings.Cut(rest, " ")
+		if unix, err := strconv.ParseInt(at, 10, 64); err == nil {
+			p.printed[id] = time.Unix(unix, 0)
+			p.names[id] = name
+		}
+	})
+	if err != nil {
+		p.unlock()
+		return nil, err
+	}
+	return p, nil
+}
+
+// unlock lets the next run waiting in loadPrintedOrders have the record
+func (p *printedOrders) unlock() {
+	if p != nil {
+		unlockFile(p.lock)
+		p.lock.Close()
+	}
+}
+
+// notRecent returns the orders that weren't rendered within the window, logging the ones it leaves out
+func (p *printedOrders) notRecent(orders []goshopify.Order, window time.Duration) []goshopify.Order {
+	var left []goshopify.Order
+	for _, o := range orders {
+		if t, ok := p.printed[o.Id]; ok && time.Since(t) < window {
+			log.Info("Skipping an order that was already rendered, use --force to render it again", "order", o.Name, "at", t.Format(time.RFC3339))
+			continue
+		}
+		left = append(left, o)
+	}
+	return left
+}
+
+// record notes that the orders were rendered now, and forgets the ones rendered before the window,
+// which can't be duplicates anymore. The new record is written to a temporary file and moved
+// into place, so a crash partway leaves the old one.
+func (p *printedOrders) record(orders []goshopify.Order, window time.Duration) error {
+	now := time.Now()
+	for _, o := range orders {
+		p.printed[o.Id] = now
+		p.names[o.Id] = o.Name
+	}
+
+	var b strings.Builder
+	for id, t := range p.printed {
+		if now.Sub(t) < window {
+			fmt.Fprintf(&b, "%d %d %s\n", id, t.Unix(), p.names[id])
+		}
+	}
+	f, err := os.CreateTemp(filepath.Dir(p.path), filepath.Base(p.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), p.path)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// redactOrder returns a copy of the order with the customer's personal details masked,
+// for sample slips. Masking keeps the length and shape of each value, so the slip
+// wraps and lays out the same as the real one.
+func redactOrder(o goshopify.Order) goshopify.Order {
+	o.Email = mask(o.Email)
+	o.Phone = mask(o.Phone)
+	o.Note = mask(o.Note)
+	o.ShippingAddress = redactAddress(o.ShippingAddress)
+	o.BillingAddress = redactAddress(o.BillingAddress)
+
+	if o.Customer != nil {
+		c := *o.Customer
+		c.FirstName = mask(c.FirstName)
+		c.LastName = mask(c.LastName)
+		c.Email = mask(c.Email)
+		c.Phone = mask(c.Phone)
+		c.Note = mask(c.Note)
+		c.DefaultAddress = redactCustomerAddress(c.DefaultAddress)
+		o.Customer = &c
+	}
+
+	// note attributes and metafields can hold anything the checkout collected
+	attributes := make([]goshopify.NoteAttribute, len(o.NoteAttributes))
+	for i, a := range o.NoteAttributes {
+		attributes[i] = goshopify.NoteAttribute{Name: a.Name, Value: maskValue(a.Value)}
+	}
+	o.NoteAttributes = attributes
+
+	metafields := make([]goshopify.Metafield, len(o.Metafields))
+	for i, m := range o.Metafields {
+		m.Value = maskValue(m.Value)
+		metafields[i] = m
+	}
+	o.Metafields = metafields
+
+	// the card company and the (already masked) number are enough to identify a customer's card
+	transactions := make([]goshopify.Transaction, len(o.Transactions))
+	for i, t := range o.Transactions {
+		if t.PaymentDetails != nil {
+			d := *t.PaymentDetails
+			d.CreditCardNumber = mask(d.CreditCardNumber)
+			d.CreditCardBin = mask(d.CreditCardBin)
+			t.PaymentDetails = &d
+		}
+		transactions[i] = t
+	}
+	o.Transactions = transactions
+	return o
+}
+
+// redactAddress masks the parts of an address that identify the person.
+// The city, region, zip and country are kept so it still reads like an address.
+func redactAddress(a *goshopify.Address) *goshopify.Address {
+	if a == nil {
+		return nil
+	}
+	r := *a
+	r.FirstName = mask(r.FirstName)
+	r.LastName = mask(r.LastName)
+	r.Name = mask(r.Name)
+	r.Company = mask(r.Company)
+	r.Address1 = mask(r.Address1)
+	r.Address2 = mask(r.Address2)
+	r.Phone = mask(r.Phone)
+	r.Latitude, r.Longitude = 0, 0
+	return &r
+}
+
+// redactCustomerAddress masks a customer's address the same way as redactAddress
+func redactCustomerAddress(a *goshopify.CustomerAddress) *goshopify.CustomerAddress {
+	if a == nil {
+		return nil
+	}
+	r := *a
+	r.FirstName = mask(r.FirstName)
+	r.LastName = mask(r.LastName)
+	r.Name = mask(r.Name)
+	r.Company = mask(r.Company)
+	r.Address1 = mask(r.Address1)
+	r.Address2 = mask(r.Address2)
+	r.Phone = mask(r.Phone)
+	return &r
+}
+
+// mask replaces letters with x (or X) and digits with 0, leaving spaces and punctuation
+func mask(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsUpper(r):
+			return 'X'
+		case unicode.IsLetter(r):
+			return 'x'
+		case unicode.IsDigit(r):
+			return '0'
+		}
+		return r
+	}, s)
+}
+
+// maskValue masks a note attribute or metafield value, whatever its type
+func maskValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return mask(fmt.Sprint(v))
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// resumeState is the --resume record of the orders a batch already rendered, a line per order
+// like "5551234567 #1001" in a file named after the batch
+type resumeState struct {
+	path string
+	done map[uint64]bool
+}
+
+// resumeKey identifies a batch by everything that picks its orders and where its slips go,
+// so running the same command again finds the same state
+func (r *RenderCmd) resumeKey(shop string) string {
+	intPtr := func(n *int) string {
+		if n == nil {
+			return ""
+		}
+		return strconv.Itoa(*n)
+	}
+	// an upload's --outfile is a temporary file, so it's the bucket URL that stays the same
+	outfile := r.OutFilename
+	if r.bucket != nil {
+		outfile = r.bucket.url(outfile)
+	}
+	parts := []string{
+		shop, outfile, r.OutputDir, r.Zip, r.Format,
+		strconv.Itoa(r.OrderOffset), strconv.Itoa(r.Count), strconv.FormatBool(r.Draft),
+		intPtr(r.QueueOffset), intPtr(r.FromOldest),
+		r.Status, r.FulfillmentStatus, r.UpdatedAfter, r.CustomerEmail, r.Query, r.Tag,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// resumeDir returns the directory the --resume state files are kept in
+func resumeDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "packingslipper", "resume"), nil
+}
+
+// loadResumeState reads the state of the batch, which is empty if it hasn't rendered anything yet.
+// With fresh (--no-resume) the old state is thrown away first.
+func loadResumeState(key string, fresh bool) (*resumeState, error) {
+	dir, err := resumeDir()
+	if err != nil {
+		return nil, err
+	}
+	state := &resumeState{path: filepath.Join(dir, key), done: map[uint64]bool{}}
+	if fresh {
+		if err := os.Remove(state.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return state, nil
+	}
+
+	err = readOrderRecords(state.path, func(id uint64, _ string) {
+		state.done[id] = true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resume state: %w", err)
+	}
+	return state, nil
+}
+
+// readOrderRecords calls fn with each line of a state file like the --resume and --skip-duplicates ones,
+// which start with an order ID, and the rest of the line after it. A missing file has no lines,
+// and a line cut short by a crash is just left out.
+func readOrderRecords(path string, fn func(id uint64, rest string)) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		first, rest, _ := strings.Cut(scanner.Text(), " ")
+		if id, err := strconv.ParseUint(first, 10, 64); err == nil {
+			fn(id, rest)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
+
+// remaining returns the orders the batch hasn't rendered yet
+func (s *resumeState) remaining(orders []goshopify.Order) []goshopify.Order {
+	var left []goshopify.Order
+	for _, o := range orders {
+		if !s.done[o.Id] {
+			left = append(left, o)
+		}
+	}
+	return left
+}
+
+// record adds the orders to the state once their slips are written
+func (s *resumeState) record(orders []goshopify.Order) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, o := range orders {
+		if !s.done[o.Id] {
+			fmt.Fprintf(&b, "%d %s\n", o.Id, o.Name)
+			s.done[o.Id] = true
+		}
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
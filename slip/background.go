@@ -0,0 +1,87 @@
+package slip
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// how opaque the watermark is, so it stays behind the content without hiding it
+const watermarkAlpha = 0.25
+
+// drawBackground fills the page with the background color and draws the watermark,
+// so that everything drawn after it sits on top. Monochrome slips get neither, since they'd print gray.
+func (p *myPdf) drawBackground(cfg Config) error {
+	if p.monochrome {
+		if cfg.Page.Watermark != "" {
+			p.warn("Leaving the watermark off the monochrome slip", "watermark", cfg.Page.Watermark)
+		}
+		return nil
+	}
+
+	if cfg.Page.Background != "" {
+		r, g, b, err := parseColor(cfg.Page.Background)
+		if err != nil {
+			return err
+		}
+		p.SetFillColor(r, g, b)
+		p.RectFromUpperLeftWithStyle(0, 0, p.page.W, p.page.H, "F")
+		p.SetFillColor(0, 0, 0)
+	}
+
+	if cfg.Page.Watermark != "" {
+		return p.drawWatermark(cfg.Page.Watermark)
+	}
+	return nil
+}
+
+// drawWatermark writes the text in light gray, as large as it can be along the diagonal of the page
+func (p *myPdf) drawWatermark(text string) error {
+	// measure at the body size, then scale up to most of the diagonal
+	if err := p.SetFont(fontStyleName[bold], "", fontSize); err != nil {
+		return err
+	}
+	width, err := p.MeasureTextWidth(text)
+	if err != nil {
+		return err
+	}
+	diagonal := math.Hypot(p.page.W, p.page.H)
+	size := fontSize * diagonal * 0.8 / width
+	if err := p.SetFont(fontStyleName[bold], "", size); err != nil {
+		return err
+	}
+	width = width * size / fontSize
+
+	p.SetTextColor(128, 128, 128)
+
+	cx, cy := p.page.W/2, p.page.H/2
+	p.Rotate(math.Atan2(p.page.H, p.page.W)*180/math.Pi, cx, cy)
+	p.SetXY(cx-width/2, cy-size/2)
+	err = p.CellWithOption(nil, text, gopdf.CellOption{
+		Align:        gopdf.Left | gopdf.Top,
+		Transparency: &gopdf.Transparency{Alpha: watermarkAlpha, BlendModeType: gopdf.NormalBlendMode},
+	})
+	p.RotateReset()
+
+	p.setTextColor(p.colors.text)
+	if err != nil {
+		return err
+	}
+	return p.SetFont(fontStyleName[regular], "", p.fontSize)
+}
+
+// parseColor parses a color written as #rrggbb
+func parseColor(s string) (uint8, uint8, uint8, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid color %q (use #rrggbb)", s)
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid color %q (use #rrggbb)", s)
+	}
+	return uint8(n >> 16), uint8(n >> 8), uint8(n), nil
+}
@@ -0,0 +1,49 @@
+package slip
+
+import (
+	"fmt"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// backorderLine returns a line like "BACKORDERED: ship 1 of 2" for an item that can't all be shipped,
+// which is when Shopify's fulfillable quantity is short of what's left to ship by at least
+// items.backorder.min-short. What's left is the quantity less the units already fulfilled or refunded,
+// since the fulfillable quantity doesn't count those either. It returns nothing for the other items,
+// or without items.backorder.show.
+//
+// The fulfillable quantity only says how many units Shopify can't fulfill, not why, so an item
+// whose fulfillment order is on hold is flagged too, and one that's oversold but allowed to be
+// (continue selling when out of stock) isn't.
+func (cfg Config) backorderLine(lineItem goshopify.LineItem) string {
+	if !cfg.Items.Backorder.Show {
+		return ""
+	}
+	left := lineItem.Quantity - cfg.settled[lineItem.Id]
+	short := left - lineItem.FulfillableQuantity
+	if short <= 0 || short < cfg.Items.Backorder.MinShort {
+		return ""
+	}
+	return fmt.Sprintf("%s %s %d %s %d", cfg.Labels.Backordered, cfg.Labels.Ship, max(lineItem.FulfillableQuantity, 0), cfg.Labels.Of, left)
+}
+
+// settledQuantities returns how many of each of the order's line items were fulfilled, by line item ID,
+// and refunded too if withRefunds. Cancelled and failed fulfillments don't count.
+func settledQuantities(order goshopify.Order, withRefunds bool) map[uint64]int {
+	settled := map[uint64]int{}
+	for _, fulfillment := range order.Fulfillments {
+		switch fulfillment.Status {
+		case "cancelled", "error", "failure":
+			continue
+		}
+		for _, lineItem := range fulfillment.LineItems {
+			settled[lineItem.Id] += lineItem.Quantity
+		}
+	}
+	if withRefunds {
+		for id, n := range refundedQuantities(order) {
+			settled[id] += n
+		}
+	}
+	return settled
+}
@@ -0,0 +1,93 @@
+package slip
+
+import "fmt"
+
+// code128Patterns are the widths of the bars and spaces of each Code 128 symbol, in modules,
+// starting with a bar. Each one is 11 modules wide, except the stop symbol's 13.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128Stop   = 106
+	// the blank space a scanner needs on each side of the bars
+	code128QuietZone = 10
+	// the widest a module is drawn, so a barcode on a wide page isn't stretched further than it needs to be
+	maxBarcodeModule = 1.5 // points
+)
+
+// code128 returns the widths of the bars and spaces of the text as a Code 128 barcode, in modules,
+// starting with a bar. Code set B covers printable ASCII, which order names like #1001 are.
+func code128(text string) ([]int, error) {
+	symbols := []int{code128StartB}
+	checksum := code128StartB
+	for i, r := range text {
+		if r < ' ' || r > '~' {
+			return nil, fmt.Errorf("%q can't be in a Code 128 barcode", r)
+		}
+		value := int(r - ' ')
+		symbols = append(symbols, value)
+		checksum += (i + 1) * value
+	}
+	symbols = append(symbols, checksum%103, code128Stop)
+
+	var widths []int
+	for _, s := range symbols {
+		for _, c := range code128Patterns[s] {
+			widths = append(widths, int(c-'0'))
+		}
+	}
+	return widths, nil
+}
+
+// barcode draws the text as a Code 128 barcode in the text width, a line and a half tall,
+// and moves the cursor below it. Text that can't be encoded gets a warning instead.
+func (p *myPdf) barcode(text string) {
+	widths, err := code128(text)
+	if err != nil {
+		p.warn("Can't draw the barcode", "text", text, "err", err)
+		return
+	}
+	modules := 2 * code128QuietZone
+	for _, w := range widths {
+		modules += w
+	}
+	space := p.textSpace()
+	module := min(space/float64(modules), maxBarcodeModule)
+
+	// it's aligned like the text, when it's narrower than the space for it
+	left := p.MarginLeft()
+	x := left
+	switch p.align {
+	case alignCenter:
+		x += (space - float64(modules)*module) / 2
+	case alignRight:
+		x += space - float64(modules)*module
+	}
+	x += code128QuietZone * module
+	y := p.GetY()
+	height := 1.5 * p.lineHeight()
+	p.SetFillColor(0, 0, 0)
+	for i, w := range widths {
+		// the even ones are bars, the odd ones the spaces between them
+		if i%2 == 0 {
+			p.RectFromUpperLeftWithStyle(x, y, float64(w)*module, height, "F")
+		}
+		x += float64(w) * module
+	}
+	p.SetXY(left, y+height+p.lineHeight()/4)
+}
+
+// barcode leaves the barcode out, since plain text can't draw one
+func (t *textSlip) barcode(text string) {}
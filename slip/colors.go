@@ -0,0 +1,53 @@
+package slip
+
+import "fmt"
+
+// colorScheme is the colors config, parsed. Anything not set is black.
+type colorScheme struct {
+	text, heading, accent [3]uint8
+}
+
+// colorScheme parses the colors config
+func (cfg Config) colorScheme() (colorScheme, error) {
+	var c colorScheme
+	for _, color := range []struct {
+		name  string
+		value string
+		rgb   *[3]uint8
+	}{
+		{"text", cfg.Colors.Text, &c.text},
+		{"heading", cfg.Colors.Heading, &c.heading},
+		{"accent", cfg.Colors.Accent, &c.accent},
+	} {
+		if color.value == "" {
+			continue
+		}
+		r, g, b, err := parseColor(color.value)
+		if err != nil {
+			return colorScheme{}, fmt.Errorf("colors %s: %w", color.name, err)
+		}
+		*color.rgb = [3]uint8{r, g, b}
+	}
+	return c, nil
+}
+
+// setTextColor sets the color of the text written after it
+func (p *myPdf) setTextColor(c [3]uint8) {
+	p.SetTextColor(c[0], c[1], c[2])
+}
+
+// writeHeading writes a bold heading in the heading color, then goes back to regular text
+func (p *myPdf) writeHeading(s string) {
+	p.setTextColor(p.colors.heading)
+	p.changeFontStyle(bold)
+	p.writeLine(s + "\n")
+	p.changeFontStyle(regular)
+	p.setTextColor(p.colors.text)
+}
+
+// writeHeading writes a heading, which is only bold in plain text
+func (t *textSlip) writeHeading(s string) {
+	t.changeFontStyle(bold)
+	t.writeLine(s + "\n")
+	t.changeFontStyle(regular)
+}
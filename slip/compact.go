@@ -0,0 +1,81 @@
+package slip
+
+import (
+	"fmt"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// the fields a compact slip can have, in the order they're written by default
+var compactFieldNames = []string{"order", "name", "barcode", "date", "items", "destination", "line-items"}
+
+// the fields a compact slip has unless the config's compact fields says otherwise
+var defaultCompactFields = []string{"order", "name", "barcode"}
+
+// compactFields returns the fields the compact slip has
+func (cfg Config) compactFields() []string {
+	if len(cfg.Compact.Fields) == 0 {
+		return defaultCompactFields
+	}
+	return cfg.Compact.Fields
+}
+
+// writeCompact writes the compact slip: the fields from the config a line each, the order in bold,
+// and nothing else but the test order banner
+func writeCompact(w slipWriter, order goshopify.Order, cfg Config) error {
+	return w.section("compact", func() error {
+		if IsTestOrder(order) {
+			cfg.warn("Order is a test order", "order", order.Name)
+			w.changeFontStyle(bold)
+			w.writeLineMax(cfg.Labels.TestOrder, 1)
+			w.changeFontStyle(regular)
+		}
+		for _, field := range cfg.compactFields() {
+			switch field {
+			case "order":
+				w.changeFontStyle(bold)
+				w.writeLineMax(cfg.Labels.Order+" "+order.Name, 1)
+				w.changeFontStyle(regular)
+			case "name":
+				if name := recipientName(order); name != "" {
+					w.writeLineMax(name, 1)
+				}
+			case "barcode":
+				w.barcode(order.Name)
+			case "date":
+				if order.CreatedAt != nil {
+					w.writeLine(order.CreatedAt.Format(cfg.dateFormat()))
+				}
+			case "items":
+				total := 0
+				for _, lineItem := range order.LineItems {
+					total += lineItem.Quantity
+				}
+				w.writeLine(plural(total, cfg.Labels.SummaryItem, cfg.Labels.SummaryItems))
+			case "destination":
+				if a := order.ShippingAddress; a != nil {
+					w.writeLineMax(strings.TrimSpace(a.City+" "+a.ProvinceCode+" "+a.CountryCode), 1)
+				}
+			case "line-items":
+				for _, lineItem := range order.LineItems {
+					w.writeLineMax(fmt.Sprintf("%d x %s", lineItem.Quantity, lineItem.Name), 1)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// recipientName returns who the order goes to: the name on the shipping address, or the customer's
+func recipientName(order goshopify.Order) string {
+	if a := order.ShippingAddress; a != nil {
+		if name := strings.TrimSpace(a.FirstName + " " + a.LastName); name != "" {
+			return name
+		}
+	}
+	if c := order.Customer; c != nil {
+		return strings.TrimSpace(c.FirstName + " " + c.LastName)
+	}
+	return ""
+}
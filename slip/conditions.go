@@ -0,0 +1,246 @@
+package slip
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// the sections a condition can be put on. The test order banner is left out on purpose.
+var conditionSections = []string{"risk", "header", "from", "ship to", "pickup", "bill to", "delivery instructions", "metafields", "payment", "order discounts", "items", "returns", "signature"}
+
+// condition is one "field op value" test, like "country != US"
+type condition struct {
+	field, op, value string
+}
+
+// conditionFields are the order fields a condition can test, by what kind of value they are
+var conditionFields = map[string]string{
+	"tags":     "list",
+	"country":  "text",
+	"province": "text",
+	"currency": "text",
+	"total":    "number",
+	"items":    "number",
+	"weight":   "number",
+}
+
+// the operators for each kind of field
+var conditionOps = map[string][]string{
+	"list":   {"has", "!has"},
+	"text":   {"=", "!="},
+	"number": {"=", "!=", ">", ">=", "<", "<="},
+}
+
+// conditionWord is a word of a condition expression, and whether any of it was in double quotes
+type conditionWord struct {
+	text   string
+	quoted bool
+}
+
+// conditionWords splits the expression into words at the spaces outside of double quotes,
+// so a quoted value like "rock and roll" is one word
+func conditionWords(expr string) ([]conditionWord, error) {
+	var words []conditionWord
+	var word strings.Builder
+	inWord, quoted, inQuotes := false, false, false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			inWord, quoted, inQuotes = true, true, !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if inWord {
+				words = append(words, conditionWord{text: word.String(), quoted: quoted})
+			}
+			word.Reset()
+			inWord, quoted = false, false
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("condition %q has a quote that isn't closed", strings.TrimSpace(expr))
+	}
+	if inWord {
+		words = append(words, conditionWord{text: word.String(), quoted: quoted})
+	}
+	return words, nil
+}
+
+// parseConditions parses an expression of conditions joined by "and", like "tags has gift and total > 50".
+// A value with "and" in it has to be quoted, like tags has "rock and roll".
+func parseConditions(expr string) ([]condition, error) {
+	words, err := conditionWords(expr)
+	if err != nil {
+		return nil, err
+	}
+	var parts [][]conditionWord
+	part := []conditionWord{}
+	for _, word := range words {
+		if word.text == "and" && !word.quoted {
+			parts = append(parts, part)
+			part = []conditionWord{}
+			continue
+		}
+		part = append(part, word)
+	}
+	parts = append(parts, part)
+
+	var conditions []condition
+	for _, part := range parts {
+		texts := make([]string, len(part))
+		for i, word := range part {
+			texts[i] = word.text
+		}
+		if len(texts) < 3 {
+			return nil, fmt.Errorf(`condition %q should be a field, an operator and a value (a value with "and" in it goes in double quotes)`, strings.Join(texts, " "))
+		}
+		c := condition{field: strings.ToLower(texts[0]), op: texts[1], value: strings.Join(texts[2:], " ")}
+
+		kind, ok := conditionFields[c.field]
+		if !ok {
+			return nil, fmt.Errorf("unknown condition field %q (use tags, country, province, currency, total, items or weight)", c.field)
+		}
+		if !slices.Contains(conditionOps[kind], c.op) {
+			return nil, fmt.Errorf("%s can't be compared with %q (use %s)", c.field, c.op, strings.Join(conditionOps[kind], ", "))
+		}
+		if kind == "number" {
+			if _, err := strconv.ParseFloat(c.value, 64); err != nil {
+				return nil, fmt.Errorf("%s needs a number to compare with, not %q", c.field, c.value)
+			}
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// checkSectionConditions checks that the conditions are on sections that exist and can all be parsed
+func (cfg Config) checkSectionConditions() error {
+	names := make([]string, 0, len(cfg.SectionConditions))
+	for name := range cfg.SectionConditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.Contains(conditionSections, name) {
+			return fmt.Errorf("unknown section %q in section-conditions (use %s)", name, strings.Join(conditionSections, ", "))
+		}
+		if _, err := parseConditions(cfg.SectionConditions[name]); err != nil {
+			return fmt.Errorf("section-conditions for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// holds reports whether the order passes the condition
+func (c condition) holds(order goshopify.Order) bool {
+	switch conditionFields[c.field] {
+	case "list":
+		has := false
+		for _, tag := range strings.Split(order.Tags, ",") {
+			if strings.EqualFold(strings.TrimSpace(tag), c.value) {
+				has = true
+			}
+		}
+		return has == (c.op == "has")
+	case "text":
+		equal := strings.EqualFold(conditionText(order, c.field), c.value)
+		return equal == (c.op == "=")
+	}
+
+	n := conditionNumber(order, c.field)
+	want, _ := strconv.ParseFloat(c.value, 64)
+	switch c.op {
+	case "=":
+		return n == want
+	case "!=":
+		return n != want
+	case ">":
+		return n > want
+	case ">=":
+		return n >= want
+	case "<":
+		return n < want
+	}
+	return n <= want
+}
+
+// conditionText returns the value of a text field of the order
+func conditionText(order goshopify.Order, field string) string {
+	switch field {
+	case "currency":
+		return order.Currency
+	}
+	if order.ShippingAddress == nil {
+		return ""
+	}
+	if field == "country" {
+		return order.ShippingAddress.CountryCode
+	}
+	return order.ShippingAddress.ProvinceCode
+}
+
+// conditionNumber returns the value of a number field of the order
+func conditionNumber(order goshopify.Order, field string) float64 {
+	switch field {
+	case "total":
+		if order.TotalPrice == nil {
+			return 0
+		}
+		f, _ := order.TotalPrice.Float64()
+		return f
+	case "weight":
+		return float64(order.TotalWeight)
+	}
+	items := 0
+	for _, lineItem := range order.LineItems {
+		items += lineItem.Quantity
+	}
+	return float64(items)
+}
+
+// conditionalWriter leaves out the sections whose conditions the order doesn't pass
+type conditionalWriter struct {
+	slipWriter
+	order goshopify.Order
+	cfg   Config
+}
+
+// shows reports whether the order passes all of the section's conditions
+func (w conditionalWriter) shows(name string) bool {
+	expr, ok := w.cfg.SectionConditions[name]
+	if !ok {
+		return true
+	}
+	conditions, err := parseConditions(expr)
+	if err != nil {
+		// Validate reports these, so just leave the section as it would be without the condition
+		return true
+	}
+	for _, c := range conditions {
+		if !c.holds(w.order) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w conditionalWriter) section(name string, write func() error) error {
+	if !w.shows(name) {
+		return nil
+	}
+	return w.slipWriter.section(name, write)
+}
+
+func (w conditionalWriter) region(name string, height float64, write func() error) error {
+	if !w.shows(name) {
+		return nil
+	}
+	return w.slipWriter.region(name, height, write)
+}
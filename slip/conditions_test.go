@@ -0,0 +1,64 @@
+package slip
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+func TestParseConditions(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []condition
+	}{
+		{expr: "tags has gift", want: []condition{{"tags", "has", "gift"}}},
+		{expr: "country != US and total > 100", want: []condition{{"country", "!=", "US"}, {"total", ">", "100"}}},
+		{expr: "tags has gift wrap", want: []condition{{"tags", "has", "gift wrap"}}},
+		{expr: `tags has "rock and roll"`, want: []condition{{"tags", "has", "rock and roll"}}},
+		{expr: `tags !has "rock and roll" and items >= 3`, want: []condition{{"tags", "!has", "rock and roll"}, {"items", ">=", "3"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseConditions(tt.expr)
+			if err != nil {
+				t.Fatalf("parseConditions: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConditionsErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "tags has rock and roll", want: "double quotes"},
+		{expr: `tags has "rock and roll`, want: "isn't closed"},
+		{expr: "colour = red", want: "unknown condition field"},
+		{expr: "total > lots", want: "needs a number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseConditions(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error about %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotedConditionHolds(t *testing.T) {
+	conditions, err := parseConditions(`tags has "rock and roll"`)
+	if err != nil {
+		t.Fatalf("parseConditions: %v", err)
+	}
+	order := goshopify.Order{Tags: "gift, Rock and Roll"}
+	if !conditions[0].holds(order) {
+		t.Errorf("%+v doesn't hold for tags %q", conditions[0], order.Tags)
+	}
+}
@@ -0,0 +1,50 @@
+package slip
+
+import "fmt"
+
+// CoverOrder is an order's line on the cover page
+type CoverOrder struct {
+	Name     string
+	Customer string
+	Items    int
+}
+
+// drawCover writes the cover page: a heading with the number of orders, then a line per order
+// with a box to tick, going on to more pages if they don't all fit on one
+func (p *myPdf) drawCover(cfg Config) error {
+	colors, err := cfg.colorScheme()
+	if err != nil {
+		return err
+	}
+	if cfg.Monochrome.Enabled {
+		colors = colorScheme{}
+	}
+	p.colors = colors
+	p.setTextColor(p.colors.text)
+	cfg.Labels = cfg.Labels.withDefaults()
+
+	p.SetXY(p.MarginLeft(), p.MarginTop())
+	p.writeHeading(fmt.Sprintf("%s: %s", cfg.Labels.Cover, plural(len(cfg.Cover.Orders), cfg.Labels.CoverOrder, cfg.Labels.CoverOrders)))
+	p.writeLine("\n")
+
+	width := p.page.W - p.MarginRight()
+	for _, o := range cfg.Cover.Orders {
+		line := "[ ] " + o.Name
+		if o.Customer != "" {
+			line += " " + o.Customer
+		}
+		line += ", " + plural(o.Items, cfg.Labels.SummaryItem, cfg.Labels.SummaryItems)
+
+		// a line that would run past the bottom starts the next page
+		lines, err := p.SplitTextWithWordWrap(line, width)
+		if err != nil {
+			lines = []string{line}
+		}
+		if p.GetY()+float64(len(lines))*p.lineHeight() > p.page.H-p.MarginBottom() {
+			p.AddPage()
+			p.SetXY(p.MarginLeft(), p.MarginTop())
+		}
+		p.writeLine(line)
+	}
+	return p.err
+}
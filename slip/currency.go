@@ -0,0 +1,57 @@
+package slip
+
+import (
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/shopspring/decimal"
+)
+
+// currencyDecimals is how many decimal places the currencies that don't use 2 have, from ISO 4217
+var currencyDecimals = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// currencySymbols are the symbols currency style symbol puts before an amount. The currencies
+// without one keep their code after the amount, since a bare "$" or "kr" could be several currencies.
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "CN¥", "INR": "₹", "KRW": "₩",
+	"CAD": "CA$", "AUD": "A$", "NZD": "NZ$", "HKD": "HK$", "TWD": "NT$", "MXN": "MX$",
+	"BRL": "R$", "ILS": "₪", "PHP": "₱", "VND": "₫",
+}
+
+// FormatAmount returns the amount with as many decimal places as the currency (a code like USD) uses,
+// like 1500 for JPY, 12.50 for USD or 1.250 for BHD
+func FormatAmount(amount decimal.Decimal, currency string) string {
+	places, ok := currencyDecimals[strings.ToUpper(currency)]
+	if !ok {
+		places = 2
+	}
+	return amount.StringFixed(places)
+}
+
+// money returns the amount in the currency, like "12.50 USD", or "$12.50" with currency style symbol
+func (cfg Config) money(amount decimal.Decimal, currency string) string {
+	formatted := FormatAmount(amount, currency)
+	if cfg.Currency.Style == "symbol" {
+		if symbol, ok := currencySymbols[strings.ToUpper(currency)]; ok {
+			return symbol + formatted
+		}
+	}
+	if currency == "" {
+		return formatted
+	}
+	return formatted + " " + currency
+}
+
+// presentmentCurrency returns the currency the customer paid in, which is the shop's currency
+// unless the order was placed in another one
+func presentmentCurrency(order goshopify.Order) string {
+	if order.PresentmentCurrency != "" {
+		return order.PresentmentCurrency
+	}
+	return order.Currency
+}
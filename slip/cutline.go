@@ -0,0 +1,27 @@
+package slip
+
+// the dash pattern of the cut line unless the config says otherwise, in points
+const (
+	defaultCutLineDash = 6
+	defaultCutLineGap  = 4
+)
+
+// drawCutLine draws a dashed line across the whole width of the page, just above the bottom edge,
+// to show where to tear slips apart on roll stock
+func (p *myPdf) drawCutLine(cfg Config) {
+	dash := cfg.CutLine.Dash
+	if dash == 0 {
+		dash = defaultCutLineDash
+	}
+	gap := cfg.CutLine.Gap
+	if gap == 0 {
+		gap = defaultCutLineGap
+	}
+
+	p.SetLineWidth(0.5)
+	p.SetStrokeColor(p.colors.accent[0], p.colors.accent[1], p.colors.accent[2])
+	p.SetCustomLineType([]float64{dash, gap}, 0)
+	p.Line(0, p.page.H-1, p.page.W, p.page.H-1)
+	p.SetLineType("solid")
+	p.SetStrokeColor(0, 0, 0)
+}
@@ -0,0 +1,24 @@
+package slip
+
+// WithDefaults returns the config with the defaults the renderer uses for unset values filled in:
+// the English labels, the fit and readable font sizes, the cut line dashes, the monochrome threshold
+// and the plain text width.
+// Rendering doesn't need it, it's for showing what's in effect.
+func (cfg Config) WithDefaults() Config {
+	cfg.Labels = cfg.Labels.withDefaults()
+	if cfg.Fit.MinFontSize <= 0 {
+		cfg.Fit.MinFontSize = defaultMinFontSize
+	}
+	cfg.Text.MinReadableSize = cfg.minReadableSize()
+	if cfg.CutLine.Dash == 0 {
+		cfg.CutLine.Dash = defaultCutLineDash
+	}
+	if cfg.CutLine.Gap == 0 {
+		cfg.CutLine.Gap = defaultCutLineGap
+	}
+	cfg.Monochrome.Threshold = cfg.monochromeThreshold()
+	if cfg.PlainText.Width <= 0 {
+		cfg.PlainText.Width = defaultTextWidth
+	}
+	return cfg
+}
@@ -0,0 +1,41 @@
+package slip
+
+import (
+	"strings"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// the layout dates are printed in unless the config's text date-format says otherwise
+const defaultDateFormat = "Jan 2, 2006"
+
+// the layouts an estimated delivery date is read in. One that's in none of them is printed as it is.
+var deliveryDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", "2006/01/02"}
+
+// dateFormat returns the layout dates are printed in
+func (cfg Config) dateFormat() string {
+	if cfg.Text.DateFormat == "" {
+		return defaultDateFormat
+	}
+	return cfg.Text.DateFormat
+}
+
+// deliveryLine returns the header's "EST. DELIVERY: Jan 5, 2026" line, from the note attribute or
+// metafield the config's delivery section names, or nothing if the order doesn't have one
+func (cfg Config) deliveryLine(order goshopify.Order) string {
+	value := noteAttribute(order.NoteAttributes, cfg.Delivery.Attribute)
+	if value == "" && cfg.Delivery.Metafield != "" {
+		value, _ = metafieldValue(order.Metafields, cfg.Delivery.Metafield)
+	}
+	if value == "" {
+		return ""
+	}
+	for _, layout := range deliveryDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			value = t.Format(cfg.dateFormat())
+			break
+		}
+	}
+	return cfg.Labels.EstDelivery + " " + strings.TrimSpace(value)
+}
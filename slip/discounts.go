@@ -0,0 +1,72 @@
+package slip
+
+import (
+	"fmt"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// writeOrderDiscounts writes the discounts that apply to the order as a whole under a heading.
+// Discounts aimed at particular line items are left to the line items.
+func writeOrderDiscounts(w slipWriter, order goshopify.Order, cfg Config) {
+	var lines []string
+	for _, d := range order.DiscountApplications {
+		if d.TargetSelection == goshopify.DiscountTargetSelectionExplicit {
+			continue
+		}
+		lines = append(lines, discountName(d)+": "+discountValue(d, presentmentCurrency(order), cfg))
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	w.writeHeading(cfg.Labels.OrderDiscounts)
+	for _, line := range lines {
+		w.writeLine(line)
+	}
+	w.writeLine("\n\n")
+}
+
+// lineDiscountLines returns a line for each discount allocated to the line item,
+// like "Line discount (SUMMER): -2.50 USD"
+func lineDiscountLines(lineItem goshopify.LineItem, order goshopify.Order, cfg Config) []string {
+	var lines []string
+	for _, a := range lineItem.DiscountAllocations {
+		if a.Amount == nil || a.Amount.IsZero() {
+			continue
+		}
+		label := cfg.Labels.LineDiscount
+		if a.DiscountApplicationIndex >= 0 && a.DiscountApplicationIndex < len(order.DiscountApplications) {
+			label += " (" + discountName(order.DiscountApplications[a.DiscountApplicationIndex]) + ")"
+		}
+		// the amount in the customer's currency, if it was another one than the shop's
+		amount, currency := *a.Amount, order.Currency
+		if a.AmountSet != nil && a.AmountSet.PresentmentMoney.Amount != nil && a.AmountSet.PresentmentMoney.CurrencyCode != "" {
+			amount, currency = *a.AmountSet.PresentmentMoney.Amount, a.AmountSet.PresentmentMoney.CurrencyCode
+		}
+		lines = append(lines, fmt.Sprintf("%s: -%s", label, cfg.money(amount, currency)))
+	}
+	return lines
+}
+
+// discountName returns the code of a discount, or its title if it wasn't a code
+func discountName(d goshopify.DiscountApplication) string {
+	if d.Code != "" {
+		return d.Code
+	}
+	if d.Title != "" {
+		return d.Title
+	}
+	return d.Description
+}
+
+// discountValue returns how much a discount takes off, like "10% off" or "-5.00 USD"
+func discountValue(d goshopify.DiscountApplication, currency string, cfg Config) string {
+	if d.Value == nil {
+		return ""
+	}
+	if d.ValueType == goshopify.DiscountValueTypePercentage {
+		return d.Value.String() + "% off"
+	}
+	return "-" + cfg.money(*d.Value, currency)
+}
@@ -0,0 +1,85 @@
+package slip
+
+import (
+	"strings"
+	"unicode"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// what items emoji placeholder puts in place of an emoji when it isn't set
+const defaultEmojiPlaceholder = "*"
+
+// isEmoji reports whether the character is an emoji or pictographic symbol, or one of the joiners,
+// variation selectors and tags that combine them. Fonts like Arial Rounded draw them as empty boxes.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, transport, flags, skin tones
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // arrows and stars, like ⭐
+		return true
+	case r >= 0x231A && r <= 0x23FF: // watches, hourglasses and media buttons
+		return true
+	case r == 0x200D, r == 0x20E3, r >= 0xFE00 && r <= 0xFE0F, r >= 0xE0020 && r <= 0xE007F:
+		return true
+	}
+	return false
+}
+
+// replaceEmoji returns the text with each run of emoji (a ZWJ sequence like 👩‍💻 is one run)
+// replaced with the placeholder, or dropped when the placeholder is empty. The spaces an emoji
+// leaves behind are tidied up.
+func replaceEmoji(text, placeholder string) string {
+	if strings.IndexFunc(text, isEmoji) < 0 {
+		return text
+	}
+	var b strings.Builder
+	inEmoji := false
+	for _, r := range text {
+		if isEmoji(r) {
+			if !inEmoji {
+				b.WriteString(placeholder)
+			}
+			inEmoji = true
+			continue
+		}
+		inEmoji = false
+		b.WriteRune(r)
+	}
+	return strings.Join(strings.FieldsFunc(b.String(), unicode.IsSpace), " ")
+}
+
+// emojiPlaceholder returns what an emoji is replaced with: nothing with items emoji strip,
+// or the emoji-placeholder with placeholder. It's false when the emoji are left alone.
+func (cfg Config) emojiPlaceholder() (string, bool) {
+	switch cfg.Items.Emoji {
+	case "strip":
+		return "", true
+	case "placeholder":
+		if cfg.Items.EmojiPlaceholder == "" {
+			return defaultEmojiPlaceholder, true
+		}
+		return cfg.Items.EmojiPlaceholder, true
+	}
+	return "", false
+}
+
+// withoutEmoji returns the order with the emoji in its item names stripped or replaced,
+// as the items emoji setting asks
+func (cfg Config) withoutEmoji(order goshopify.Order) goshopify.Order {
+	placeholder, ok := cfg.emojiPlaceholder()
+	if !ok {
+		return order
+	}
+	lineItems := make([]goshopify.LineItem, len(order.LineItems))
+	for i, lineItem := range order.LineItems {
+		lineItem.Name = replaceEmoji(lineItem.Name, placeholder)
+		lineItem.Title = replaceEmoji(lineItem.Title, placeholder)
+		lineItem.VariantTitle = replaceEmoji(lineItem.VariantTitle, placeholder)
+		lineItems[i] = lineItem
+	}
+	order.LineItems = lineItems
+	return order
+}
@@ -0,0 +1,110 @@
+package slip
+
+import (
+	"sort"
+	"unicode"
+)
+
+// the name the fallback font is added to the PDF under
+const fallbackFont = "fallback"
+
+// fontRun is a piece of a line that's drawn in one font
+type fontRun struct {
+	text     string
+	fallback bool
+}
+
+// fontRuns splits a line into runs of characters the current font has and runs that need the fallback font.
+// Characters neither font has stay with the current font, and are noted in p.missingGlyphs.
+func (p *myPdf) fontRuns(text string) []fontRun {
+	var runs []fontRun
+	for _, r := range text {
+		fallback := false
+		if !unicode.IsSpace(r) && !p.hasGlyph(r) {
+			fallback = p.hasFallback && p.fallbackHasGlyph(r)
+			if !fallback {
+				p.missingGlyphs[r] = true
+			}
+		}
+		// spaces go with whatever run they're in, to keep switching fonts to a minimum
+		if unicode.IsSpace(r) && len(runs) > 0 {
+			fallback = runs[len(runs)-1].fallback
+		}
+		if len(runs) > 0 && runs[len(runs)-1].fallback == fallback {
+			runs[len(runs)-1].text += string(r)
+			continue
+		}
+		runs = append(runs, fontRun{text: string(r), fallback: fallback})
+	}
+	return runs
+}
+
+// hasGlyph reports whether the current font has the character
+func (p *myPdf) hasGlyph(r rune) bool {
+	ok, err := p.IsCurrFontContainGlyph(r)
+	return err == nil && ok
+}
+
+// fallbackHasGlyph reports whether the fallback font has the character
+func (p *myPdf) fallbackHasGlyph(r rune) bool {
+	if err := p.SetFont(fallbackFont, "", p.fontSize); err != nil {
+		return false
+	}
+	defer p.changeFontStyle(p.style)
+	return p.hasGlyph(r)
+}
+
+// runsWidth returns the width of the runs, each measured in its own font
+func (p *myPdf) runsWidth(runs []fontRun) (float64, error) {
+	var width float64
+	for _, run := range runs {
+		w, err := p.measureRun(run)
+		if err != nil {
+			return 0, err
+		}
+		width += w
+	}
+	return width, nil
+}
+
+func (p *myPdf) measureRun(run fontRun) (float64, error) {
+	if !run.fallback {
+		return p.MeasureTextWidth(run.text)
+	}
+	if err := p.SetFont(fallbackFont, "", p.fontSize); err != nil {
+		return 0, err
+	}
+	defer p.changeFontStyle(p.style)
+	return p.MeasureTextWidth(run.text)
+}
+
+// cellRuns draws the runs one after another, switching to the fallback font and back as needed
+func (p *myPdf) cellRuns(runs []fontRun) error {
+	for _, run := range runs {
+		if !run.fallback {
+			if err := p.Cell(nil, run.text); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.SetFont(fallbackFont, "", p.fontSize); err != nil {
+			return err
+		}
+		err := p.Cell(nil, run.text)
+		p.changeFontStyle(p.style)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// missingGlyphList returns the characters that couldn't be drawn, in order, as one string
+func (p *myPdf) missingGlyphList() string {
+	runes := make([]rune, 0, len(p.missingGlyphs))
+	for r := range p.missingGlyphs {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return string(runes)
+}
@@ -0,0 +1,46 @@
+package slip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// the font size of the hash in the footer
+const hashFontSize = 6
+
+// orderHash returns the first 8 hex digits of a SHA-256 over the parts of the order that get printed:
+// the name, the line items and the shipping address. A reprint of an unchanged order gets the same hash.
+func orderHash(order goshopify.Order) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "name:%s\n", order.Name)
+	for _, lineItem := range order.LineItems {
+		fmt.Fprintf(h, "item:%d\t%s\t%s\n", lineItem.Quantity, lineItem.SKU, lineItem.Name)
+	}
+	if a := order.ShippingAddress; a != nil {
+		fmt.Fprintf(h, "ship:%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			a.FirstName, a.LastName, a.Address1, a.Address2, a.City, a.ProvinceCode, a.Zip, a.Country)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// writeHash writes the order hash in small text at the bottom of the page, whatever the cursor position.
+// The cursor is left where it was.
+func (p *myPdf) writeHash(order goshopify.Order) error {
+	x, y := p.GetX(), p.GetY()
+
+	if err := p.SetFont(fontStyleName[regular], "", hashFontSize); err != nil {
+		return err
+	}
+	hashY := p.page.H - p.MarginBottom() - hashFontSize
+	p.SetXY(p.MarginLeft(), hashY)
+	if err := p.Cell(nil, orderHash(order)); err != nil {
+		return err
+	}
+	p.sections = append(p.sections, Section{Name: "hash", StartY: hashY, EndY: hashY + hashFontSize})
+
+	p.SetXY(x, y)
+	return p.SetFont(fontStyleName[regular], "", p.fontSize)
+}
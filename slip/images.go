@@ -0,0 +1,143 @@
+package slip
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/signintech/gopdf"
+)
+
+// preparedImages holds each image file as it's embedded, for each monochrome threshold and max-px,
+// so a combined PDF converts the logo once and gopdf embeds it once. A file that was modified since,
+// like a logo edited under --watch, is converted again. Slips can be rendered at the same time,
+// so it's only used with preparedImagesMu held.
+var (
+	preparedImages   = map[string]preparedImage{}
+	preparedImagesMu sync.Mutex
+)
+
+// preparedImage is an image file as it's embedded, and when the file was modified
+type preparedImage struct {
+	modified time.Time
+	data     []byte
+}
+
+// drawImage draws the image file like gopdf's Image, but downscaled to the images max-px,
+// and in pure black and white with monochrome
+func (p *myPdf) drawImage(fn string, x, y float64, rect *gopdf.Rect, cfg Config) error {
+	if !cfg.Monochrome.Enabled && cfg.Images.MaxPx == 0 {
+		return p.Image(fn, x, y, rect)
+	}
+	b, err := prepareImage(fn, cfg)
+	if err != nil {
+		return err
+	}
+	holder, err := gopdf.ImageHolderByBytes(b)
+	if err != nil {
+		return err
+	}
+	return p.ImageByHolder(holder, x, y, rect)
+}
+
+// prepareImage returns the image file the way it's embedded: shrunk so neither side is larger than
+// the images max-px, then thresholded with monochrome. It stays a JPEG if it was one, since photos
+// take a lot more room as PNGs.
+func prepareImage(fn string, cfg Config) ([]byte, error) {
+	threshold := 0
+	if cfg.Monochrome.Enabled {
+		threshold = cfg.monochromeThreshold()
+	}
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s@%d@%d", fn, threshold, cfg.Images.MaxPx)
+	preparedImagesMu.Lock()
+	prepared, ok := preparedImages[key]
+	preparedImagesMu.Unlock()
+	if ok && prepared.modified.Equal(info.ModTime()) {
+		return prepared.data, nil
+	}
+	img, format, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image %s: %w", fn, err)
+	}
+
+	if cfg.Images.MaxPx > 0 {
+		img = downscale(img, cfg.Images.MaxPx)
+	}
+	var buf bytes.Buffer
+	switch {
+	case cfg.Monochrome.Enabled:
+		err = png.Encode(&buf, monochromeImage(img, threshold))
+	case format == "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	preparedImagesMu.Lock()
+	preparedImages[key] = preparedImage{modified: info.ModTime(), data: buf.Bytes()}
+	preparedImagesMu.Unlock()
+	return buf.Bytes(), nil
+}
+
+// downscale returns the image shrunk so neither side is more than maxPx pixels, keeping its proportions.
+// Each pixel is the average of the pixels it covers, so thin lines in a logo fade rather than vanish.
+// An image that's small enough already is returned as it is.
+func downscale(img image.Image, maxPx int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxPx && h <= maxPx {
+		return img
+	}
+	dw, dh := maxPx, max(h*maxPx/w, 1)
+	if h > w {
+		dw, dh = max(w*maxPx/h, 1), maxPx
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			// the colors are weighted by their alpha, so transparent pixels don't darken the edges
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := src.PixOffset(sx, sy)
+					pa := int(src.Pix[i+3])
+					r += int(src.Pix[i]) * pa
+					g += int(src.Pix[i+1]) * pa
+					b += int(src.Pix[i+2]) * pa
+					a += pa
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[i] = uint8(r / a)
+				dst.Pix[i+1] = uint8(g / a)
+				dst.Pix[i+2] = uint8(b / a)
+			}
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,225 @@
+package slip
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// sortLineItems returns the line items in the order given by mode.
+// An empty mode (or "original") keeps Shopify's order.
+// The "location" mode uses the locations map of SKU prefixes to bin sort keys,
+// with items that don't match any prefix going last.
+func sortLineItems(lineItems []goshopify.LineItem, mode string, locations map[string]string) ([]goshopify.LineItem, error) {
+	sorted := make([]goshopify.LineItem, len(lineItems))
+	copy(sorted, lineItems)
+
+	var less func(a, b goshopify.LineItem) bool
+	switch mode {
+	case "", "original":
+		return sorted, nil
+	case "sku":
+		less = func(a, b goshopify.LineItem) bool { return a.SKU < b.SKU }
+	case "name":
+		less = func(a, b goshopify.LineItem) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "quantity":
+		less = func(a, b goshopify.LineItem) bool { return a.Quantity < b.Quantity }
+	case "location":
+		less = func(a, b goshopify.LineItem) bool {
+			aKey, aOk := binKey(a.SKU, locations)
+			bKey, bOk := binKey(b.SKU, locations)
+			if aOk != bOk {
+				return aOk
+			}
+			return aKey < bKey
+		}
+	default:
+		return nil, fmt.Errorf("unknown item sort %q (use original, sku, name, quantity or location)", mode)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted, nil
+}
+
+// binKey returns the sort key for the longest SKU prefix in locations that matches the SKU
+func binKey(sku string, locations map[string]string) (string, bool) {
+	longest := -1
+	key := ""
+	for prefix, k := range locations {
+		if strings.HasPrefix(sku, prefix) && len(prefix) > longest {
+			longest = len(prefix)
+			key = k
+		}
+	}
+	return key, longest >= 0
+}
+
+// writeItems writes the line items, grouped under vendor headings if the config asks for it,
+// as a table with the table layout
+func writeItems(w slipWriter, order goshopify.Order, lineItems []goshopify.LineItem, cfg Config, t *template.Template) error {
+	table := cfg.Items.Layout == "table"
+	if !cfg.Items.GroupByVendor {
+		if table {
+			writeItemTable(w, lineItems, cfg)
+			return nil
+		}
+		for _, lineItem := range lineItems {
+			if err := writeItem(w, order, lineItem, cfg, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	groups := groupByVendor(lineItems)
+	for _, group := range groups {
+		// items without a vendor only need a heading to separate them from the vendor groups
+		heading := strings.ToUpper(group.vendor)
+		if heading == "" && len(groups) > 1 {
+			heading = cfg.Labels.Other
+		}
+		if heading != "" {
+			w.writeHeading(heading)
+		}
+		if table {
+			writeItemTable(w, group.lineItems, cfg)
+			continue
+		}
+		for _, lineItem := range group.lineItems {
+			if err := writeItem(w, order, lineItem, cfg, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeItem writes a single line item, using the item-template if there is one
+func writeItem(w slipWriter, order goshopify.Order, lineItem goshopify.LineItem, cfg Config, t *template.Template) error {
+	if t != nil {
+		return writeItemTemplate(w, t, lineItem)
+	}
+
+	w.changeFontStyle(regular)
+	w.writeLine(cfg.quantityLine(lineItem))
+	w.changeFontStyle(bold)
+	if line := cfg.backorderLine(lineItem); line != "" {
+		w.writeLine(line)
+	}
+	maxLines := cfg.Items.MaxNameLines
+	if maxLines == 0 {
+		maxLines = cfg.Text.MaxLines
+	}
+	w.writeLineMax(lineItem.Name, maxLines)
+	w.changeFontStyle(regular)
+	if cfg.Items.ShowVendor && lineItem.Vendor != "" {
+		w.writeLine(cfg.Labels.Vendor + " " + lineItem.Vendor)
+	}
+	if showSKU(cfg.Items.ShowSKU, lineItem.SKU) {
+		w.writeLine(cfg.Labels.SKU + " " + lineItem.SKU)
+	}
+	if cfg.Discounts.ShowLineDiscounts {
+		for _, line := range lineDiscountLines(lineItem, order, cfg) {
+			w.writeLine(line)
+		}
+	}
+	w.writeLine("\n\n")
+	return nil
+}
+
+// showSKU reports whether an item gets a SKU line.
+// By default it's left off when the item has no SKU, rather than printing a dangling label.
+func showSKU(mode, sku string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return sku != ""
+}
+
+// quantityLine returns the quantity of a line item with its label, like "Qty 2".
+// With a quantity-property, the value of that line item property is used instead of the
+// integer Shopify quantity, so weights and volumes can be fractional.
+func (cfg Config) quantityLine(lineItem goshopify.LineItem) string {
+	label := cfg.Items.QuantityLabel
+	if label == "" {
+		label = cfg.Labels.Quantity
+	}
+	return label + " " + cfg.quantity(lineItem) + cfg.refundedNote(lineItem)
+}
+
+// quantity returns the quantity of a line item (or its quantity-property) in the quantity-format
+func (cfg Config) quantity(lineItem goshopify.LineItem) string {
+	qty := float64(lineItem.Quantity)
+	if cfg.Items.QuantityProperty != "" {
+		if v, ok := cfg.propertyQuantity(lineItem, cfg.Items.QuantityProperty); ok {
+			qty = v
+		}
+	}
+
+	if cfg.Items.QuantityFormat != "" {
+		return fmt.Sprintf(cfg.Items.QuantityFormat, qty)
+	}
+	return strconv.FormatFloat(qty, 'f', -1, 64)
+}
+
+// propertyQuantity returns the number in the named line item property.
+// The value can be a JSON number or a string holding one.
+func (cfg Config) propertyQuantity(lineItem goshopify.LineItem, name string) (float64, bool) {
+	for _, prop := range lineItem.Properties {
+		if prop.Name != name {
+			continue
+		}
+		switch v := prop.Value.(type) {
+		case float64:
+			return v, true
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				cfg.warn("Line item quantity property isn't a number", "item", lineItem.Name, "property", name, "value", v)
+				return 0, false
+			}
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+type vendorGroup struct {
+	vendor    string
+	lineItems []goshopify.LineItem
+}
+
+// groupByVendor groups the line items by vendor, in the order each vendor first appears.
+// Items without a vendor go in a final group with no vendor name.
+func groupByVendor(lineItems []goshopify.LineItem) []vendorGroup {
+	var groups []vendorGroup
+	var noVendor []goshopify.LineItem
+	index := map[string]int{}
+	for _, lineItem := range lineItems {
+		if lineItem.Vendor == "" {
+			noVendor = append(noVendor, lineItem)
+			continue
+		}
+		i, ok := index[lineItem.Vendor]
+		if !ok {
+			i = len(groups)
+			index[lineItem.Vendor] = i
+			groups = append(groups, vendorGroup{vendor: lineItem.Vendor})
+		}
+		groups[i].lineItems = append(groups[i].lineItems, lineItem)
+	}
+
+	if len(noVendor) > 0 {
+		groups = append(groups, vendorGroup{lineItems: noVendor})
+	}
+	return groups
+}
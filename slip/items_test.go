@@ -0,0 +1,54 @@
+package slip
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+func TestBlankSKU(t *testing.T) {
+	tests := []struct {
+		showSKU string
+		want    bool
+	}{
+		{showSKU: "", want: false},
+		{showSKU: "auto", want: false},
+		{showSKU: "always", want: true},
+		{showSKU: "never", want: false},
+	}
+	order := testOrder("#1001", goshopify.LineItem{Id: 1, Name: "Mug", Quantity: 1, SKU: ""})
+	for _, tt := range tests {
+		name := "show-sku " + tt.showSKU
+		if tt.showSKU == "" {
+			name = "default"
+		}
+		t.Run(name, func(t *testing.T) {
+			var cfg Config
+			cfg.Items.ShowSKU = tt.showSKU
+			var b bytes.Buffer
+			if err := RenderTexts([]goshopify.Order{order}, cfg, &b); err != nil {
+				t.Fatalf("RenderTexts: %v", err)
+			}
+			if got := strings.Contains(b.String(), defaultLabels.SKU); got != tt.want {
+				t.Errorf("SKU line printed: %v, want %v:\n%s", got, tt.want, b.String())
+			}
+		})
+	}
+}
+
+func TestSKU(t *testing.T) {
+	order := testOrder("#1001", goshopify.LineItem{Id: 1, Name: "Mug", Quantity: 1, SKU: "MUG-1"})
+	for _, showSKU := range []string{"", "auto", "always"} {
+		var cfg Config
+		cfg.Items.ShowSKU = showSKU
+		var b bytes.Buffer
+		if err := RenderTexts([]goshopify.Order{order}, cfg, &b); err != nil {
+			t.Fatalf("RenderTexts: %v", err)
+		}
+		if !strings.Contains(b.String(), defaultLabels.SKU+" MUG-1") {
+			t.Errorf("show-sku %q left off the SKU:\n%s", showSKU, b.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package slip
+
+// Labels are the words printed on the slip, so they can be translated.
+// Any label left empty uses the English default.
+type Labels struct {
+	Order                string `yaml:"order"`
+	Source               string `yaml:"source"`
+	EstDelivery          string `yaml:"est-delivery"`
+	TestOrder            string `yaml:"test-order"`
+	Risk                 string `yaml:"risk"`
+	From                 string `yaml:"from"`
+	ShipTo               string `yaml:"ship-to"`
+	PickupAt             string `yaml:"pickup-at"`
+	PickupCode           string `yaml:"pickup-code"`
+	BillTo               string `yaml:"bill-to"`
+	DeliveryInstructions string `yaml:"delivery-instructions"`
+	OrderDiscounts       string `yaml:"order-discounts"`
+	LineDiscount         string `yaml:"line-discount"`
+	Payment              string `yaml:"payment"`
+	Returns              string `yaml:"returns"`
+	CardEnding           string `yaml:"card-ending"`
+	Quantity             string `yaml:"quantity"`
+	Refunded             string `yaml:"refunded"`
+	Backordered          string `yaml:"backordered"`
+	Ship                 string `yaml:"ship"`
+	SKU                  string `yaml:"sku"`
+	Item                 string `yaml:"item"`
+	Vendor               string `yaml:"vendor"`
+	Other                string `yaml:"other"`
+	NoItems              string `yaml:"no-items"`
+	Slip                 string `yaml:"slip"`
+	Copy                 string `yaml:"copy"`
+	Of                   string `yaml:"of"`
+	Pack                 string `yaml:"pack"`
+	Cover                string `yaml:"cover"`
+
+	// the nouns of the pack summary, for one and for more than one
+	SummaryItem  string `yaml:"summary-item"`
+	SummaryItems string `yaml:"summary-items"`
+	SummarySKU   string `yaml:"summary-sku"`
+	SummarySKUs  string `yaml:"summary-skus"`
+
+	// the noun of the cover page heading, for one order and for more than one
+	CoverOrder  string `yaml:"cover-order"`
+	CoverOrders string `yaml:"cover-orders"`
+}
+
+var defaultLabels = Labels{
+	Order:                "Order",
+	Source:               "Source:",
+	EstDelivery:          "EST. DELIVERY:",
+	TestOrder:            "TEST ORDER — DO NOT SHIP",
+	Risk:                 "FRAUD RISK:",
+	From:                 "FROM",
+	ShipTo:               "SHIP TO",
+	PickupAt:             "PICKUP AT",
+	PickupCode:           "Pickup code:",
+	BillTo:               "BILL TO",
+	DeliveryInstructions: "DELIVERY INSTRUCTIONS",
+	OrderDiscounts:       "ORDER DISCOUNTS",
+	LineDiscount:         "Line discount",
+	Payment:              "PAYMENT",
+	Returns:              "RETURNS",
+	CardEnding:           "ending",
+	Quantity:             "Qty",
+	Refunded:             "refunded",
+	Backordered:          "BACKORDERED:",
+	Ship:                 "ship",
+	SKU:                  "SKU:",
+	Item:                 "Item",
+	Vendor:               "Vendor:",
+	Other:                "OTHER",
+	NoItems:              "No items",
+	Slip:                 "Slip",
+	Copy:                 "COPY",
+	Of:                   "of",
+	Pack:                 "PACK:",
+	Cover:                "BATCH",
+
+	SummaryItem:  "item",
+	SummaryItems: "items",
+	SummarySKU:   "SKU",
+	SummarySKUs:  "SKUs",
+
+	CoverOrder:  "order",
+	CoverOrders: "orders",
+}
+
+// withDefaults returns the labels with the empty ones filled in from the English defaults
+func (l Labels) withDefaults() Labels {
+	fill := func(s *string, def string) {
+		if *s == "" {
+			*s = def
+		}
+	}
+	fill(&l.Order, defaultLabels.Order)
+	fill(&l.Source, defaultLabels.Source)
+	fill(&l.EstDelivery, defaultLabels.EstDelivery)
+	fill(&l.TestOrder, defaultLabels.TestOrder)
+	fill(&l.Risk, defaultLabels.Risk)
+	fill(&l.From, defaultLabels.From)
+	fill(&l.ShipTo, defaultLabels.ShipTo)
+	fill(&l.PickupAt, defaultLabels.PickupAt)
+	fill(&l.PickupCode, defaultLabels.PickupCode)
+	fill(&l.BillTo, defaultLabels.BillTo)
+	fill(&l.Quantity, defaultLabels.Quantity)
+	fill(&l.Refunded, defaultLabels.Refunded)
+	fill(&l.Backordered, defaultLabels.Backordered)
+	fill(&l.Ship, defaultLabels.Ship)
+	fill(&l.DeliveryInstructions, defaultLabels.DeliveryInstructions)
+	fill(&l.OrderDiscounts, defaultLabels.OrderDiscounts)
+	fill(&l.LineDiscount, defaultLabels.LineDiscount)
+	fill(&l.Payment, defaultLabels.Payment)
+	fill(&l.Returns, defaultLabels.Returns)
+	fill(&l.CardEnding, defaultLabels.CardEnding)
+	fill(&l.SKU, defaultLabels.SKU)
+	fill(&l.Item, defaultLabels.Item)
+	fill(&l.Vendor, defaultLabels.Vendor)
+	fill(&l.Other, defaultLabels.Other)
+	fill(&l.NoItems, defaultLabels.NoItems)
+	fill(&l.Slip, defaultLabels.Slip)
+	fill(&l.Copy, defaultLabels.Copy)
+	fill(&l.Of, defaultLabels.Of)
+	fill(&l.Pack, defaultLabels.Pack)
+	fill(&l.SummaryItem, defaultLabels.SummaryItem)
+	fill(&l.SummaryItems, defaultLabels.SummaryItems)
+	fill(&l.SummarySKU, defaultLabels.SummarySKU)
+	fill(&l.SummarySKUs, defaultLabels.SummarySKUs)
+	fill(&l.Cover, defaultLabels.Cover)
+	fill(&l.CoverOrder, defaultLabels.CoverOrder)
+	fill(&l.CoverOrders, defaultLabels.CoverOrders)
+	return l
+}
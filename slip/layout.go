@@ -0,0 +1,93 @@
+package slip
+
+import (
+	"io"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// Layout describes where the content ended up on the page, for tuning vertical-space and page sizes
+type Layout struct {
+	PageHeight float64
+	FinalY     float64 // the cursor position after the last line
+	Overflowed bool    // whether the content ran past the bottom margin
+	FontSize   float64 // the body font size, which --fit may have reduced
+	Sections   []Section
+}
+
+// Section is the vertical extent of one part of the slip, in points from the top of the page
+type Section struct {
+	Name   string
+	StartY float64
+	EndY   float64
+}
+
+// RenderSlipLayout writes a packing slip PDF for the order to w, like RenderSlip,
+// and also returns the layout of what it rendered
+func RenderSlipLayout(order goshopify.Order, cfg Config, w io.Writer) (*Layout, error) {
+	p, err := renderFit(order, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PDFMetadata.Enabled {
+		p.setInfo([]goshopify.Order{order}, cfg)
+	}
+
+	if err := p.Write(w); err != nil {
+		return nil, err
+	}
+
+	return &Layout{
+		PageHeight: p.page.H,
+		FinalY:     p.GetY(),
+		Overflowed: p.overflowed(),
+		FontSize:   p.fontSize,
+		Sections:   p.sections,
+	}, nil
+}
+
+// markSection records a section that started at startY and ends at the current cursor position
+func (p *myPdf) markSection(name string, startY float64) {
+	p.sections = append(p.sections, Section{Name: name, StartY: startY, EndY: p.GetY()})
+}
+
+// section writes a section and records where it started and ended on the page
+func (p *myPdf) section(name string, write func() error) error {
+	start := p.GetY()
+	if err := write(); err != nil {
+		return err
+	}
+	if p.err != nil {
+		return p.err
+	}
+	p.markSection(name, start)
+	return nil
+}
+
+// region writes a section in an area height points tall, with a rule along the bottom of it,
+// and carries on below the area. Anything that doesn't fit runs over the rule with a warning.
+func (p *myPdf) region(name string, height float64, write func() error) error {
+	if height == 0 {
+		return p.section(name, write)
+	}
+
+	start := p.GetY()
+	if err := write(); err != nil {
+		return err
+	}
+	if p.err != nil {
+		return p.err
+	}
+	end := start + height
+	// the blank line that ends every section can go past the rule without losing anything
+	if p.GetY()-p.lineHeight() > end {
+		p.warn("Content runs past the bottom of its area", "section", name, "height", height)
+	}
+	p.SetLineWidth(0.5)
+	p.SetStrokeColor(p.colors.accent[0], p.colors.accent[1], p.colors.accent[2])
+	p.Line(p.MarginLeft(), end, p.page.W-p.MarginRight(), end)
+	p.SetStrokeColor(0, 0, 0)
+	p.SetXY(p.MarginLeft(), end+p.lineHeight())
+	p.sections = append(p.sections, Section{Name: name, StartY: start, EndY: end})
+	return nil
+}
@@ -0,0 +1,36 @@
+package slip
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/signintech/gopdf"
+)
+
+// setInfo fills in the PDF's document properties with the orders, so document management systems
+// can index the slips without reading them. gopdf has no Keywords property, so the order IDs go in the Subject.
+func (p *myPdf) setInfo(orders []goshopify.Order, cfg Config) {
+	names := make([]string, len(orders))
+	subjects := make([]string, len(orders))
+	for i, o := range orders {
+		names[i] = o.Name
+		subjects[i] = fmt.Sprintf("%s (ID %d)", o.Name, o.Id)
+		if o.CreatedAt != nil {
+			subjects[i] += " " + o.CreatedAt.Format("2006-01-02")
+		}
+	}
+
+	subject := "Order " + strings.Join(subjects, ", ")
+	if cfg.PDFMetadata.Shop != "" {
+		subject += " from " + cfg.PDFMetadata.Shop
+	}
+	p.SetInfo(gopdf.PdfInfo{
+		Title:        "Packing slip " + strings.Join(names, ", "),
+		Author:       cfg.PDFMetadata.Shop,
+		Subject:      subject,
+		Creator:      "packingslipper",
+		CreationDate: time.Now(),
+	})
+}
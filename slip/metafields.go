@@ -0,0 +1,40 @@
+package slip
+
+import (
+	"fmt"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// writeMetafields writes the value of each metafield in the config under its label.
+// Metafields the order doesn't have are skipped.
+func writeMetafields(w slipWriter, order goshopify.Order, cfg Config) {
+	for _, key := range cfg.Metafields.Keys {
+		value, ok := metafieldValue(order.Metafields, key)
+		if !ok {
+			cfg.warn("Order doesn't have the metafield", "order", order.Name, "metafield", key)
+			continue
+		}
+
+		label := cfg.Metafields.Labels[key]
+		if label == "" {
+			label = key
+		}
+		w.writeHeading(label)
+		w.writeLine(value + "\n\n")
+	}
+}
+
+// metafieldValue returns the value of the metafield named by a NAMESPACE.KEY string.
+// Empty values count as missing, since there's nothing to print.
+func metafieldValue(metafields []goshopify.Metafield, key string) (string, bool) {
+	namespace, name, _ := strings.Cut(key, ".")
+	for _, m := range metafields {
+		if m.Namespace == namespace && m.Key == name && m.Value != nil {
+			value := strings.TrimSpace(fmt.Sprint(m.Value))
+			return value, value != ""
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,36 @@
+package slip
+
+import (
+	"image"
+	"image/color"
+)
+
+// the gray level (0-255) below which a pixel of an image turns black in monochrome, unless the config says otherwise
+const defaultMonochromeThreshold = 128
+
+// monochromeThreshold returns the monochrome threshold from the config, or the default
+func (cfg Config) monochromeThreshold() int {
+	if cfg.Monochrome.Threshold == 0 {
+		return defaultMonochromeThreshold
+	}
+	return cfg.Monochrome.Threshold
+}
+
+// monochromeImage returns the image with each pixel black or white: black if it's darker than the
+// threshold once it's laid over a white page, so transparent pixels come out white
+func monochromeImage(img image.Image, threshold int) *image.Gray {
+	bounds := img.Bounds()
+	mono := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// the colors are premultiplied, so adding the rest of the white gives the pixel on white
+			white := 0xffff - a
+			gray := (19595*(r+white) + 38470*(g+white) + 7471*(b+white) + 1<<15) >> 24
+			if int(gray) >= threshold {
+				mono.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return mono
+}
@@ -0,0 +1,45 @@
+package slip
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// writeNoteAttributes writes the order's note attributes as "Key: Value" lines under a heading.
+// With a keys allowlist in the config only those attributes are written, so internal ones stay off the slip.
+// Nothing is written if there aren't any.
+func writeNoteAttributes(w slipWriter, order goshopify.Order, cfg Config) {
+	lines := noteAttributeLines(order.NoteAttributes, cfg.NoteAttributes.Keys)
+	if len(lines) == 0 {
+		return
+	}
+
+	w.writeHeading(cfg.Labels.DeliveryInstructions)
+	for _, line := range lines {
+		w.writeLine(line)
+	}
+	w.writeLine("\n\n")
+}
+
+// noteAttributeLines returns a line for each attribute with a value, in the order's order,
+// skipping any that aren't in keys when keys isn't empty
+func noteAttributeLines(attributes []goshopify.NoteAttribute, keys []string) []string {
+	var lines []string
+	for _, a := range attributes {
+		if len(keys) > 0 && !slices.Contains(keys, a.Name) {
+			continue
+		}
+		if a.Value == nil {
+			continue
+		}
+		value := strings.TrimSpace(fmt.Sprint(a.Value))
+		if value == "" {
+			continue
+		}
+		lines = append(lines, a.Name+": "+value)
+	}
+	return lines
+}
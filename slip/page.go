@@ -0,0 +1,103 @@
+package slip
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// pageSizes are the named paper and label sizes that page.size can use, in points
+var pageSizes = map[string]gopdf.Rect{
+	"a5":     {W: 419.53, H: 595.28},
+	"a6":     {W: 297.64, H: 419.53},
+	"a7":     {W: 209.76, H: 297.64},
+	"letter": {W: 612, H: 792},
+	"4x6in":  {W: 288, H: 432},
+	"4x8in":  {W: 288, H: 576},
+	"2x7in":  {W: 144, H: 504},
+	"2x1in":  {W: 144, H: 72},
+}
+
+// pageRect returns the page size from the config.
+// A named size is used as the starting point (2x7in if there isn't one, or 2x1in for a compact slip),
+// and an explicit width or height overrides it. Landscape swaps the two
+// after that, so width and height always describe the portrait page.
+func (cfg Config) pageRect() (gopdf.Rect, error) {
+	rect := gopdf.Rect{W: pageWidth, H: pageHeight}
+
+	size := cfg.Page.Size
+	if size == "" && cfg.Compact.Enabled {
+		size = "2x1in"
+	}
+	if size != "" {
+		named, ok := pageSizes[strings.ToLower(size)]
+		if !ok {
+			return rect, fmt.Errorf("unknown page size %q (supported sizes: %s)", cfg.Page.Size, strings.Join(pageSizeNames(), ", "))
+		}
+		rect = named
+	}
+
+	if cfg.Page.Width > 0 {
+		rect.W = cfg.Page.Width
+	}
+	if cfg.Page.Height > 0 {
+		rect.H = cfg.Page.Height
+	}
+
+	switch strings.ToLower(cfg.Page.Orientation) {
+	case "", "portrait":
+	case "landscape":
+		rect.W, rect.H = rect.H, rect.W
+	default:
+		return rect, fmt.Errorf("unknown page orientation %q (use portrait or landscape)", cfg.Page.Orientation)
+	}
+	return rect, nil
+}
+
+// how far, in points, a page can be from a named size and still count as that size
+const pageSizeTolerance = 2
+
+// PageSizeWarning returns a warning if the page doesn't match any of the named sizes,
+// in either orientation. Label printers tend to scale or crop a page that doesn't match the media,
+// so the warning names the nearest size. It returns "" when the page matches.
+func (cfg Config) PageSizeWarning() (string, error) {
+	page, err := cfg.pageRect()
+	if err != nil {
+		return "", err
+	}
+
+	nearest := ""
+	best := math.Inf(1)
+	for _, name := range pageSizeNames() {
+		size := pageSizes[name]
+		d := min(
+			math.Abs(page.W-size.W)+math.Abs(page.H-size.H),
+			math.Abs(page.W-size.H)+math.Abs(page.H-size.W),
+		)
+		if d < best {
+			best = d
+			nearest = name
+		}
+	}
+
+	size := pageSizes[nearest]
+	if math.Abs(page.W-size.W) <= pageSizeTolerance && math.Abs(page.H-size.H) <= pageSizeTolerance ||
+		math.Abs(page.W-size.H) <= pageSizeTolerance && math.Abs(page.H-size.W) <= pageSizeTolerance {
+		return "", nil
+	}
+	return fmt.Sprintf("page is %gx%gpt, which isn't a standard size (the nearest is %s, %gx%gpt)",
+		page.W, page.H, nearest, size.W, size.H), nil
+}
+
+// pageSizeNames returns the supported page size names in alphabetical order
+func pageSizeNames() []string {
+	names := make([]string, 0, len(pageSizes))
+	for name := range pageSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,56 @@
+package slip
+
+import (
+	"testing"
+
+	"github.com/signintech/gopdf"
+)
+
+func TestPageRect(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        string
+		width       float64
+		height      float64
+		orientation string
+		want        gopdf.Rect
+	}{
+		{name: "default", want: gopdf.Rect{W: pageWidth, H: pageHeight}},
+		{name: "default landscape", orientation: "landscape", want: gopdf.Rect{W: pageHeight, H: pageWidth}},
+		{name: "named", size: "4x6in", want: gopdf.Rect{W: 288, H: 432}},
+		{name: "named portrait", size: "4x6in", orientation: "portrait", want: gopdf.Rect{W: 288, H: 432}},
+		{name: "named landscape", size: "4x6in", orientation: "Landscape", want: gopdf.Rect{W: 432, H: 288}},
+		{name: "width overrides size", size: "a6", width: 200, want: gopdf.Rect{W: 200, H: 419.53}},
+		{name: "landscape after overrides", width: 100, height: 300, orientation: "landscape", want: gopdf.Rect{W: 300, H: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			cfg.Page.Size = tt.size
+			cfg.Page.Width = tt.width
+			cfg.Page.Height = tt.height
+			cfg.Page.Orientation = tt.orientation
+			got, err := cfg.pageRect()
+			if err != nil {
+				t.Fatalf("pageRect: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("pageRect = %vx%v, want %vx%v", got.W, got.H, tt.want.W, tt.want.H)
+			}
+		})
+	}
+}
+
+func TestPageRectErrors(t *testing.T) {
+	var cfg Config
+	cfg.Page.Orientation = "sideways"
+	if _, err := cfg.pageRect(); err == nil {
+		t.Error("pageRect accepted orientation sideways")
+	}
+
+	cfg = Config{}
+	cfg.Page.Size = "postcard"
+	if _, err := cfg.pageRect(); err == nil {
+		t.Error("pageRect accepted size postcard")
+	}
+}
@@ -0,0 +1,79 @@
+package slip
+
+import (
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// writePayment writes how the order was paid for under a heading, a line per payment.
+// Orders without any transactions (or whose transactions couldn't be read) are left out.
+func writePayment(w slipWriter, order goshopify.Order, cfg Config) {
+	lines := paymentLines(order.Transactions, cfg)
+	if len(lines) == 0 {
+		return
+	}
+
+	w.writeHeading(cfg.Labels.Payment)
+	for _, line := range lines {
+		w.writeLine(line)
+	}
+	w.writeLine("\n\n")
+}
+
+// paymentLines returns a line for each successful sale or capture, like
+// "shopify_payments: Visa ending 4242, 25.00 USD". Authorizations are only listed
+// when nothing was captured yet, so a partly captured payment isn't counted twice.
+func paymentLines(transactions []goshopify.Transaction, cfg Config) []string {
+	var paid, authorized []string
+	for _, t := range transactions {
+		if t.Status != "" && t.Status != "success" {
+			continue
+		}
+		switch t.Kind {
+		case "sale", "capture":
+			paid = append(paid, paymentLine(t, cfg))
+		case "authorization":
+			authorized = append(authorized, paymentLine(t, cfg))
+		}
+	}
+	if len(paid) > 0 {
+		return paid
+	}
+	return authorized
+}
+
+// paymentLine describes one transaction: the gateway, the card if there was one, and the amount
+func paymentLine(t goshopify.Transaction, cfg Config) string {
+	line := t.Gateway
+	if line == "" {
+		line = t.SourceName
+	}
+	if card := cardDescription(t.PaymentDetails, cfg.Labels); card != "" {
+		line += ": " + card
+	}
+	if t.Amount != nil {
+		line += ", " + cfg.money(*t.Amount, t.Currency)
+	}
+	return line
+}
+
+// cardDescription returns the card company and its last 4 digits, like "Visa ending 4242".
+// Shopify already masks the rest of the number.
+func cardDescription(details *goshopify.PaymentDetails, labels Labels) string {
+	if details == nil {
+		return ""
+	}
+	number := strings.TrimSpace(details.CreditCardNumber)
+	last4 := ""
+	if len(number) >= 4 {
+		last4 = number[len(number)-4:]
+	}
+	switch {
+	case details.CreditCardCompany != "" && last4 != "":
+		return details.CreditCardCompany + " " + labels.CardEnding + " " + last4
+	case last4 != "":
+		return labels.CardEnding + " " + last4
+	}
+	return details.CreditCardCompany
+}
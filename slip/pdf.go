@@ -0,0 +1,354 @@
+package slip
+
+import (
+	"embed"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/signintech/gopdf"
+)
+
+//go:embed arialrounded.ttf
+//go:embed arialroundedbold.ttf
+var embeddedFiles embed.FS
+
+type fontStyle int
+
+const (
+	bold fontStyle = iota
+	regular
+)
+
+var fontStyleName = map[fontStyle]string{
+	bold:    "bold",
+	regular: "regular",
+}
+
+type alignment int
+
+const (
+	alignLeft alignment = iota
+	alignCenter
+	alignRight
+)
+
+var alignmentNames = map[string]alignment{
+	"left":   alignLeft,
+	"center": alignCenter,
+	"right":  alignRight,
+}
+
+// myPdf embeds gopdf.GoPdf so I can create a WriteLine method later
+// https://stackoverflow.com/questions/28800672/how-to-add-new-methods-to-an-existing-type-in-go
+type myPdf struct {
+	*gopdf.GoPdf
+	page     gopdf.Rect
+	fontSize float64
+	align    alignment
+	maxLines int
+	sections []Section
+
+	// textWidth is how wide the text can be from the left margin, when it's narrower than the page
+	textWidth float64
+
+	// style is the current font style, to go back to after a run in the fallback font
+	style         fontStyle
+	hasFallback   bool
+	missingGlyphs map[rune]bool
+
+	colors     colorScheme
+	monochrome bool
+
+	// err is the first error changeFontStyle had, which its sections and render return
+	err error
+	// warnings are the ones render had, which renderFit logs for the font size it keeps
+	warnings []warning
+}
+
+// the default page size, for 2x7 Dymo labels
+const pageWidth = 144  // points
+const pageHeight = 504 // points
+const lineSpacing = 13
+const fontSize = 10
+
+// gopdf's default margins, which the slips keep
+const pageMargin = 10 // points
+
+// the smallest font size --fit will shrink to unless the config says otherwise
+const defaultMinFontSize = 6
+
+// the end of a line that was cut off
+const ellipsis = "…"
+
+// gopdf places images at 128dpi when it isn't given a rect
+const imageDPI = 128
+
+// loadEmbeddedFont returns an fs.File from an embedded ttf file
+func loadEmbeddedFont(fn string) (fs.File, error) {
+	f, err := embeddedFiles.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f, nil
+}
+
+// createPDF sets up a gopdf.GoPdf document for the packing slip label
+// using the given page size and body font size.
+// The fallback font file, if there is one, is used for characters the other fonts don't have.
+func createPDF(page gopdf.Rect, size float64, fonts fontFiles) (*myPdf, error) {
+	// create the pdf struct
+	pdf := &myPdf{GoPdf: &gopdf.GoPdf{}, page: page, fontSize: size, style: regular, missingGlyphs: map[rune]bool{}}
+
+	pdf.Start(gopdf.Config{PageSize: page})
+	pdf.AddPage()
+
+	if fonts.regular != "" {
+		// an installed font, from fonts.by-name
+		if err := pdf.AddTTFFont("regular", fonts.regular); err != nil {
+			return nil, fmt.Errorf("failed to load font %s: %w", fonts.regular, err)
+		}
+		bold := fonts.bold
+		if err := pdf.AddTTFFont("bold", bold); err != nil {
+			warnBoldFont(bold, err)
+			bold = fonts.regular
+			if err := pdf.AddTTFFont("bold", bold); err != nil {
+				return nil, fmt.Errorf("failed to load font %s: %w", fonts.regular, err)
+			}
+		}
+		if err := pdf.checkFontWidth("regular", fonts.regular); err != nil {
+			return nil, err
+		}
+		if err := pdf.checkFontWidth("bold", bold); err != nil {
+			return nil, err
+		}
+	} else if err := pdf.addEmbeddedFonts(); err != nil {
+		return nil, err
+	}
+	if fonts.fallback != "" {
+		if err := pdf.AddTTFFont(fallbackFont, fonts.fallback); err != nil {
+			return nil, fmt.Errorf("failed to load fallback font %s: %w", fonts.fallback, err)
+		}
+		pdf.hasFallback = true
+	}
+
+	if err := pdf.SetFont("regular", "", size); err != nil {
+		return nil, err
+	}
+
+	return pdf, nil
+}
+
+// fontCheckText is measured with each installed font once it's loaded, since a file in the wrong format
+// can load without an error and then draw everything with no width, leaving the slip blank
+const fontCheckText = "Order 1001"
+
+// checkFontWidth returns an error if the font added under the name draws fontCheckText with no width.
+// The fallback font isn't checked, since it may well only have the characters it's there for.
+func (p *myPdf) checkFontWidth(name, fn string) error {
+	if err := p.SetFont(name, "", p.fontSize); err != nil {
+		return err
+	}
+	w, err := p.MeasureTextWidth(fontCheckText)
+	if err != nil || w <= 0 {
+		return fmt.Errorf("font %s draws %q with no width, so it doesn't seem to have usable glyphs (is it a TrueType font?)", fn, fontCheckText)
+	}
+	return nil
+}
+
+// addEmbeddedFonts adds the embedded Arial Rounded as the regular and bold fonts
+func (p *myPdf) addEmbeddedFonts() error {
+	if err := p.addEmbeddedFont("regular", "arialrounded.ttf"); err != nil {
+		return err
+	}
+	if err := p.addEmbeddedFont("bold", "arialroundedbold.ttf"); err != nil {
+		warnBoldFont("arialroundedbold.ttf", err)
+		return p.addEmbeddedFont("bold", "arialrounded.ttf")
+	}
+	return nil
+}
+
+// addEmbeddedFont adds an embedded ttf file to the PDF under the name
+func (p *myPdf) addEmbeddedFont(name, fn string) error {
+	f, err := loadEmbeddedFont(fn)
+	if err != nil {
+		return err
+	}
+	container := &gopdf.FontContainer{}
+	if err := container.AddTTFFontByReader(name, f); err != nil {
+		return err
+	}
+	return p.AddTTFFontFromFontContainer(name, container)
+}
+
+// boldFontFailures are the bold font files that couldn't be loaded, so each one is only warned about once
+// rather than for every page or --fit attempt. Slips can be rendered at the same time,
+// so it's only used with boldFontFailuresMu held.
+var (
+	boldFontFailures   = map[string]bool{}
+	boldFontFailuresMu sync.Mutex
+)
+
+// warnBoldFont warns that the bold font file couldn't be loaded and the regular font is used instead
+func warnBoldFont(fn string, err error) {
+	boldFontFailuresMu.Lock()
+	defer boldFontFailuresMu.Unlock()
+	if boldFontFailures[fn] {
+		return
+	}
+	boldFontFailures[fn] = true
+	Logger.Warn("Can't load the bold font, using the regular font for bold text", "font", fn, "err", err)
+}
+
+// logoRect returns the size the logo will be drawn at.
+// It uses the natural size of the image unless width is given, in which case it scales to that width.
+func logoRect(fn string, width float64) (*gopdf.Rect, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	imgCfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logo %s: %w", fn, err)
+	}
+
+	rect := &gopdf.Rect{
+		W: float64(imgCfg.Width) * 72 / imageDPI,
+		H: float64(imgCfg.Height) * 72 / imageDPI,
+	}
+	if width > 0 {
+		rect.H = rect.H * width / rect.W
+		rect.W = width
+	}
+	return rect, nil
+}
+
+// writeLine writes a line to the PDF.
+// It wraps long strings at based on the page width minus the right margin (or the text width),
+// and places each wrapped line according to the text alignment.
+// More than 1 trailing newline characters are converted to additional line breaks.
+func (p *myPdf) writeLine(s string) {
+	p.writeLineMax(s, p.maxLines)
+}
+
+// writeLineMax writes a line like writeLine, but cuts it off with an ellipsis
+// after maxLines wrapped lines. A maxLines of 0 doesn't cut it off.
+func (p *myPdf) writeLineMax(s string, maxLines int) {
+	trimmed := strings.TrimRight(s, "\n")
+	newlines := len(s) - len(trimmed)
+
+	// if there is any text after trimming the newlines
+	// then split it at the page width before writing it to a cell
+	if trimmed != "" {
+		width := p.wrapWidth()
+		texts, _ := p.SplitTextWithWordWrap(trimmed, width)
+		if maxLines > 0 && len(texts) > maxLines {
+			texts = texts[:maxLines]
+			texts[maxLines-1] = truncateLine(texts[maxLines-1], func(t string) bool {
+				w, err := p.MeasureTextWidth(t)
+				return err == nil && w <= width
+			})
+		}
+		for _, text := range texts {
+			runs := p.fontRuns(text)
+			p.SetX(p.lineX(runs))
+			_ = p.cellRuns(runs)
+			p.Br(p.lineHeight())
+		}
+	}
+
+	if newlines > 1 {
+		p.Br(p.lineHeight() * float64(newlines-1))
+	}
+}
+
+// truncateLine shortens the line until it fits with an ellipsis on the end
+func truncateLine(line string, fits func(string) bool) string {
+	runes := []rune(strings.TrimRight(line, " "))
+	for len(runes) > 0 && !fits(string(runes)+ellipsis) {
+		runes = []rune(strings.TrimRight(string(runes[:len(runes)-1]), " "))
+	}
+	return string(runes) + ellipsis
+}
+
+// wrapWidth returns the width lines wrap at: the text width from the config,
+// or the page width minus the right margin
+func (p *myPdf) wrapWidth() float64 {
+	if p.textWidth > 0 {
+		return p.textWidth
+	}
+	return p.page.W - p.MarginRight()
+}
+
+// textSpace returns the width lines are aligned in from the left margin: the text width
+// from the config, or the space between the margins
+func (p *myPdf) textSpace() float64 {
+	if p.textWidth > 0 {
+		return p.textWidth
+	}
+	return p.page.W - p.MarginRight() - p.MarginLeft()
+}
+
+// lineX returns the X position of a line of text between the margins (or in the text width).
+// Lines that are wider than that space start at the left margin.
+func (p *myPdf) lineX(runs []fontRun) float64 {
+	left := p.MarginLeft()
+	if p.align == alignLeft {
+		return left
+	}
+	width, err := p.runsWidth(runs)
+	if err != nil {
+		return left
+	}
+	space := p.textSpace()
+	if p.align == alignCenter {
+		return left + max(space-width, 0)/2
+	}
+	return left + max(space-width, 0)
+}
+
+// lineHeight returns the line spacing, scaled along with the font size
+func (p *myPdf) lineHeight() float64 {
+	return lineSpacing * p.fontSize / fontSize
+}
+
+// overflowed reports whether the cursor has gone past the bottom margin of the page
+func (p *myPdf) overflowed() bool {
+	return p.GetY() > p.page.H-p.MarginBottom()
+}
+
+// sameAddress reports whether two addresses would print the same lines
+func sameAddress(a, b *goshopify.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.FirstName == b.FirstName &&
+		a.LastName == b.LastName &&
+		a.Address1 == b.Address1 &&
+		a.Address2 == b.Address2 &&
+		a.City == b.City &&
+		a.ProvinceCode == b.ProvinceCode &&
+		a.Zip == b.Zip &&
+		a.Country == b.Country
+}
+
+// changeFontStyle sets the font to either bold or regular.
+// If it can't be done, the error is kept for the section being written to return.
+func (p *myPdf) changeFontStyle(s fontStyle) {
+	if err := p.SetFont(fontStyleName[s], "", p.fontSize); err != nil {
+		if p.err == nil {
+			p.err = fmt.Errorf("failed to set the %s font: %w", fontStyleName[s], err)
+		}
+		return
+	}
+	p.style = s
+}
@@ -0,0 +1,88 @@
+package slip
+
+import (
+	"fmt"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// pickupDetails is what a local pickup order's slip shows in place of the shipping address
+type pickupDetails struct {
+	location string
+	address  *goshopify.Address
+	code     string
+}
+
+// pickupOrder returns the pickup details of an order that's collected rather than shipped: one with the
+// pickup.location-attribute note attribute, or with a shipping line that says it's a pickup, as Shopify's
+// local pickup does. The shipping address of those orders is the location's, not the customer's.
+func (cfg Config) pickupOrder(order goshopify.Order) (pickupDetails, bool) {
+	details := pickupDetails{
+		address: order.ShippingAddress,
+		code:    noteAttribute(order.NoteAttributes, cfg.Pickup.CodeAttribute),
+	}
+	if location := noteAttribute(order.NoteAttributes, cfg.Pickup.LocationAttribute); location != "" {
+		details.location = location
+		return details, true
+	}
+	for _, line := range order.ShippingLines {
+		if isPickup(line.Code) || isPickup(line.Source) || isPickup(line.Title) {
+			// the title of a pickup line is usually the location's name
+			if !isPickup(line.Title) {
+				details.location = line.Title
+			}
+			return details, true
+		}
+	}
+	return pickupDetails{}, false
+}
+
+// isPickup reports whether a shipping line field says it's a pickup, like "Local Pickup" or "pick-up"
+func isPickup(s string) bool {
+	s = strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(s))
+	return strings.Contains(s, "pickup")
+}
+
+// noteAttribute returns the trimmed value of the named note attribute, or "" if the name is empty
+// or the order doesn't have it
+func noteAttribute(attributes []goshopify.NoteAttribute, name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, a := range attributes {
+		if a.Name == name && a.Value != nil {
+			return strings.TrimSpace(fmt.Sprint(a.Value))
+		}
+	}
+	return ""
+}
+
+// writePickup writes the location the order is collected from under a PICKUP AT heading,
+// with its address, the pickup code and the pickup.instructions
+func writePickup(w slipWriter, details pickupDetails, cfg Config) {
+	w.writeHeading(cfg.Labels.PickupAt)
+	if details.location != "" {
+		w.writeLine(details.location)
+	}
+	if a := details.address; a != nil {
+		if a.Address1 != "" {
+			w.writeLine(a.Address1)
+		}
+		if a.Address2 != "" {
+			w.writeLine(a.Address2)
+		}
+		if city := strings.TrimSpace(a.City + " " + a.ProvinceCode + " " + a.Zip); city != "" {
+			w.writeLine(city)
+		}
+	}
+	if details.code != "" {
+		w.changeFontStyle(bold)
+		w.writeLine(cfg.Labels.PickupCode + " " + details.code)
+		w.changeFontStyle(regular)
+	}
+	if instructions := strings.TrimSpace(cfg.Pickup.Instructions); instructions != "" {
+		w.writeLine(instructions)
+	}
+	w.writeLine("\n\n")
+}
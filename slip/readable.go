@@ -0,0 +1,25 @@
+package slip
+
+import "fmt"
+
+// the smallest font size that's still readable on a label, unless the config says otherwise
+const defaultMinReadableSize = 6
+
+// minReadableSize returns text.min-readable-size, or the default if it isn't set
+func (cfg Config) minReadableSize() float64 {
+	if cfg.Text.MinReadableSize > 0 {
+		return cfg.Text.MinReadableSize
+	}
+	return defaultMinReadableSize
+}
+
+// FontSizeWarnings returns a warning for each fixed font size the config uses that's smaller than
+// text.min-readable-size. Sizes that fit only might shrink to are warned about when a slip actually
+// shrinks that far.
+func (cfg Config) FontSizeWarnings() []string {
+	var warnings []string
+	if cfg.Hash.Show && hashFontSize < cfg.minReadableSize() {
+		warnings = append(warnings, fmt.Sprintf("the hash is printed at %gpt, below the min-readable-size of %gpt", float64(hashFontSize), cfg.minReadableSize()))
+	}
+	return warnings
+}
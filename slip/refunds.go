@@ -0,0 +1,45 @@
+package slip
+
+import (
+	"fmt"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// refundedQuantities returns how many of each line item have been refunded, by line item ID
+func refundedQuantities(order goshopify.Order) map[uint64]int {
+	refunded := map[uint64]int{}
+	for _, refund := range order.Refunds {
+		for _, refundItem := range refund.RefundLineItems {
+			refunded[refundItem.LineItemId] += refundItem.Quantity
+		}
+	}
+	return refunded
+}
+
+// netQuantities returns the order with the refunded quantities taken off its line items,
+// so the slip shows what's left to ship. Items that were refunded completely are left out.
+func netQuantities(order goshopify.Order, refunded map[uint64]int) goshopify.Order {
+	if len(refunded) == 0 {
+		return order
+	}
+	var lineItems []goshopify.LineItem
+	for _, lineItem := range order.LineItems {
+		lineItem.Quantity -= refunded[lineItem.Id]
+		if lineItem.Quantity <= 0 {
+			continue
+		}
+		lineItems = append(lineItems, lineItem)
+	}
+	order.LineItems = lineItems
+	return order
+}
+
+// refundedNote returns the " (2 refunded)" that goes after the quantity of a partly refunded item
+// with show-refunded, or nothing
+func (cfg Config) refundedNote(lineItem goshopify.LineItem) string {
+	if !cfg.Items.ShowRefunded || cfg.refunded[lineItem.Id] == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d %s)", cfg.refunded[lineItem.Id], cfg.Labels.Refunded)
+}
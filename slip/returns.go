@@ -0,0 +1,19 @@
+package slip
+
+import "strings"
+
+// writeReturns writes the text.returns instructions under a heading, with the returns-url after them.
+// Nothing is written if there are no instructions.
+func writeReturns(w slipWriter, cfg Config) {
+	instructions := strings.TrimSpace(cfg.Text.Returns)
+	if instructions == "" {
+		return
+	}
+
+	w.writeHeading(cfg.Labels.Returns)
+	w.writeLine(instructions)
+	if cfg.Text.ReturnsURL != "" {
+		w.writeLine(cfg.Text.ReturnsURL)
+	}
+	w.writeLine("\n\n")
+}
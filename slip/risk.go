@@ -0,0 +1,83 @@
+package slip
+
+import (
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/signintech/gopdf"
+)
+
+// the banner colors for each risk recommendation
+var riskColors = map[goshopify.OrderRiskRecommendation][3]uint8{
+	goshopify.OrderRecommendationCancel:      {200, 30, 30},
+	goshopify.OrderRecommendationInvestigate: {230, 140, 0},
+	goshopify.OrderRecommendationAccept:      {30, 140, 60},
+}
+
+// HighestRisk returns the most serious recommendation of the risks: cancel, then investigate, then accept.
+// It returns "" if there aren't any.
+func HighestRisk(risks []goshopify.OrderRisk) goshopify.OrderRiskRecommendation {
+	rank := map[goshopify.OrderRiskRecommendation]int{
+		goshopify.OrderRecommendationAccept:      1,
+		goshopify.OrderRecommendationInvestigate: 2,
+		goshopify.OrderRecommendationCancel:      3,
+	}
+	var highest goshopify.OrderRiskRecommendation
+	for _, r := range risks {
+		if rank[r.Recommendation] > rank[highest] {
+			highest = r.Recommendation
+		}
+	}
+	return highest
+}
+
+// writeRisk writes the order's risk recommendation as a banner in its color.
+// Orders without a recommendation get nothing.
+func writeRisk(w slipWriter, order goshopify.Order, cfg Config) {
+	risk, ok := cfg.Risk.Recommendations[order.Id]
+	if !ok || risk == "" {
+		return
+	}
+	if risk != goshopify.OrderRecommendationAccept {
+		cfg.warn("Shopify recommends checking this order for fraud before shipping it", "order", order.Name, "recommendation", risk)
+	}
+	w.banner(cfg.Labels.Risk+" "+strings.ToUpper(string(risk)), riskColors[risk])
+}
+
+// banner draws the text in white bold letters, centered on a bar of the color across the page
+// (black on a monochrome slip). Text too wide for the page wraps onto more lines rather than being cut off.
+func (p *myPdf) banner(text string, color [3]uint8) {
+	if p.monochrome {
+		color = [3]uint8{}
+	}
+	left := p.MarginLeft()
+	width := p.page.W - p.MarginRight() - left
+	pad := p.lineHeight() / 4
+	y := p.GetY()
+
+	p.changeFontStyle(bold)
+	lines, err := p.SplitTextWithWordWrap(text, width)
+	if err != nil {
+		lines = []string{text}
+	}
+	height := float64(len(lines))*p.lineHeight() + 2*pad
+
+	p.SetFillColor(color[0], color[1], color[2])
+	p.RectFromUpperLeftWithStyle(left, y, width, height, "F")
+	p.SetFillColor(0, 0, 0)
+
+	p.SetTextColor(255, 255, 255)
+	for i, line := range lines {
+		p.SetXY(left, y+pad+float64(i)*p.lineHeight())
+		_ = p.CellWithOption(&gopdf.Rect{W: width, H: p.lineHeight()}, line, gopdf.CellOption{Align: gopdf.Center | gopdf.Middle})
+	}
+	p.setTextColor(p.colors.text)
+	p.changeFontStyle(regular)
+
+	p.SetXY(left, y+height+p.lineHeight())
+}
+
+// banner writes the text between asterisks, since plain text has no colors
+func (t *textSlip) banner(text string, color [3]uint8) {
+	t.writeLine("*** " + text + " ***\n\n")
+}
@@ -0,0 +1,555 @@
+// Package slip renders a packing slip PDF for a Shopify order.
+//
+// The renderer only reads a handful of order fields, so callers can build
+// a synthetic goshopify.Order instead of fetching one from Shopify:
+//
+//   - Test, Gateway and PaymentGatewayNames, to put a banner on test orders
+//   - Id, to find the order's recommendation in Config.Risk.Recommendations
+//   - Tags, Currency, TotalPrice and TotalWeight, for the Config.SectionConditions that test them
+//   - Name and CreatedAt, for the header (or whatever fields Config.Text.HeaderTemplate refers to)
+//   - ShippingAddress, and BillingAddress when Config.Billing.Show is set (whatever fields of them
+//     Config.Text.AddressTemplate refers to)
+//   - LineItems, using Quantity, Name and SKU (or whatever fields Config.Text.ItemTemplate refers to),
+//     and Properties when Config.Items.QuantityProperty is set
+//   - Metafields, when Config.Metafields.Keys is set
+//   - NoteAttributes, when Config.NoteAttributes.Show is set
+//   - Transactions, when Config.Payment.Show is set (Shopify only includes them in an order when asked)
+//   - DiscountApplications, Currency, PresentmentCurrency and the line items' DiscountAllocations,
+//     when Config.Discounts asks for them
+package slip
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/charmbracelet/log"
+)
+
+// Logger receives warnings about the order while rendering. It discards them by default.
+var Logger = log.New(io.Discard)
+
+// Config is the layout of the slip, as read from configuration.yaml
+type Config struct {
+	Page struct {
+		Size        string  `yaml:"size"`
+		Width       float64 `yaml:"width"`
+		Height      float64 `yaml:"height"`
+		Orientation string  `yaml:"orientation"`
+		Background  string  `yaml:"background"`
+		Watermark   string  `yaml:"watermark"`
+	} `yaml:"page"`
+
+	// Presets are named sets of settings, written like the rest of the config,
+	// that the command line's --preset lays over it
+	Presets map[string]interface{} `yaml:"presets"`
+
+	// the colors of the text, the headings and the rules, as #rrggbb (default black)
+	Colors struct {
+		Text    string `yaml:"text"`
+		Heading string `yaml:"heading"`
+		Accent  string `yaml:"accent"`
+	} `yaml:"colors"`
+
+	// Monochrome makes everything black, and the logo and stamp black and white, with pixels lighter
+	// than the Threshold (0-255) turning white
+	Monochrome struct {
+		Enabled   bool `yaml:"enabled"`
+		Threshold int  `yaml:"threshold"`
+	} `yaml:"monochrome"`
+
+	Logo struct {
+		Filename      string  `yaml:"filename"`
+		VerticalSpace int     `yaml:"vertical-space"`
+		Width         float64 `yaml:"width"`
+		Gap           float64 `yaml:"gap"`
+	} `yaml:"logo"`
+
+	// Images shrinks the logo and stamp so neither side is more than MaxPx pixels before they're embedded,
+	// keeping the PDF small when the files are much bigger than they're printed
+	Images struct {
+		MaxPx int `yaml:"max-px"`
+	} `yaml:"images"`
+
+	Stamp struct {
+		Filename string  `yaml:"filename"`
+		Width    float64 `yaml:"width"`
+		Align    string  `yaml:"align"`
+	} `yaml:"stamp"`
+
+	Text struct {
+		Salutation      string  `yaml:"salutation"`
+		Signature       string  `yaml:"signature"`
+		VerticalSpace   int     `yaml:"vertical-space"`
+		ItemTemplate    string  `yaml:"item-template"`
+		HeaderTemplate  string  `yaml:"header-template"`
+		AddressTemplate string  `yaml:"address-template"`
+		HeaderHeight    float64 `yaml:"header-height"`
+		DateFormat      string  `yaml:"date-format"`
+		Width           float64 `yaml:"width"`
+		Align           string  `yaml:"align"`
+		MaxLines        int     `yaml:"max-lines"`
+		MinReadableSize float64 `yaml:"min-readable-size"`
+		Returns         string  `yaml:"returns"`
+		ReturnsURL      string  `yaml:"returns-url"`
+	} `yaml:"text"`
+
+	// Compact makes a minimal slip for tiny labels, 2x1in unless the page says otherwise: just the Fields
+	// (order, name, barcode, date, items, destination and line-items), without the logo or anything else
+	Compact struct {
+		Enabled bool     `yaml:"enabled"`
+		Fields  []string `yaml:"fields"`
+	} `yaml:"compact"`
+
+	Billing struct {
+		Show       bool `yaml:"show"`
+		AlwaysShow bool `yaml:"always-show"`
+	} `yaml:"billing"`
+
+	CutLine struct {
+		Show bool    `yaml:"show"`
+		Dash float64 `yaml:"dash"`
+		Gap  float64 `yaml:"gap"`
+	} `yaml:"cut-line"`
+
+	// PDFMetadata puts the order names, IDs and dates in the PDF's document properties when it's enabled.
+	// Shop comes from the command line, not the config file.
+	PDFMetadata struct {
+		Enabled bool   `yaml:"enabled"`
+		Shop    string `yaml:"-"`
+	} `yaml:"pdf-metadata"`
+
+	Hash struct {
+		Show bool `yaml:"show"`
+	} `yaml:"hash"`
+
+	Fit struct {
+		Enabled     bool    `yaml:"enabled"`
+		MinFontSize float64 `yaml:"min-font-size"`
+	} `yaml:"fit"`
+
+	Items struct {
+		Summary          bool              `yaml:"summary"`
+		Layout           string            `yaml:"layout"`
+		Sort             string            `yaml:"sort"`
+		Locations        map[string]string `yaml:"locations"`
+		ShowVendor       bool              `yaml:"show-vendor"`
+		GroupByVendor    bool              `yaml:"group-by-vendor"`
+		ShowSKU          string            `yaml:"show-sku"`
+		MaxNameLines     int               `yaml:"max-name-lines"`
+		QuantityLabel    string            `yaml:"quantity-label"`
+		QuantityFormat   string            `yaml:"quantity-format"`
+		QuantityProperty string            `yaml:"quantity-property"`
+		NetQuantities    bool              `yaml:"net-quantities"`
+		ShowRefunded     bool              `yaml:"show-refunded"`
+		Emoji            string            `yaml:"emoji"`
+		EmojiPlaceholder string            `yaml:"emoji-placeholder"`
+		Backorder        struct {
+			Show     bool `yaml:"show"`
+			MinShort int  `yaml:"min-short"`
+		} `yaml:"backorder"`
+	} `yaml:"items"`
+
+	Fonts struct {
+		ByName   string `yaml:"by-name"`
+		Fallback string `yaml:"fallback"`
+	} `yaml:"fonts"`
+
+	Labels Labels `yaml:"labels"`
+
+	PlainText struct {
+		Width int `yaml:"width"`
+	} `yaml:"plain-text"`
+
+	// refunded is how many of each line item were refunded, by line item ID, with net-quantities
+	refunded map[uint64]int
+	// settled is how many of each line item don't need shipping anymore, by line item ID, for the
+	// backorder lines: the ones already fulfilled, and the refunded ones net-quantities didn't take off
+	settled map[uint64]int
+	// warnings is where render holds the warnings about a PDF slip, when they're logged once it fits
+	warnings *[]warning
+
+	// From is printed as a FROM address above SHIP TO when it's set, like the location
+	// a fulfillment order ships from. It comes from the command line, not the config file.
+	From *goshopify.Address `yaml:"-"`
+
+	// Batch numbers the slip "Slip 3 of 12" when Total is set. It comes from the command line, not the config file,
+	// and RenderSlips counts up from Number for each page.
+	Batch struct {
+		Number int `yaml:"-"`
+		Total  int `yaml:"-"`
+	} `yaml:"-"`
+
+	// Cover puts a page listing the orders before the slips in RenderSlips when Orders is set.
+	// It comes from the command line, not the config file.
+	Cover struct {
+		Orders []CoverOrder `yaml:"-"`
+	} `yaml:"-"`
+
+	// Copies is how many copies of each slip RenderSlips and RenderTexts make, one after the other,
+	// and with Label set each one says "COPY 1 of 2". With Number set, only that copy is made.
+	// Total and Number come from the command line.
+	Copies struct {
+		Label  bool `yaml:"label"`
+		Number int  `yaml:"-"`
+		Total  int  `yaml:"-"`
+	} `yaml:"copies"`
+
+	Payment struct {
+		Show bool `yaml:"show"`
+	} `yaml:"payment"`
+
+	// Risk puts a banner with Shopify's fraud risk recommendation at the top of the slip when Show is set.
+	// Orders don't carry their risks, so the recommendations by order ID come from the command line,
+	// and orders without one get no banner.
+	Risk struct {
+		Show            bool                                         `yaml:"show"`
+		Recommendations map[uint64]goshopify.OrderRiskRecommendation `yaml:"-"`
+	} `yaml:"risk"`
+
+	// Delivery puts the estimated delivery date a shipping app left on the order in the header,
+	// from the note attribute named by Attribute or the metafield named by Metafield (NAMESPACE.KEY)
+	Delivery struct {
+		Attribute string `yaml:"attribute"`
+		Metafield string `yaml:"metafield"`
+	} `yaml:"delivery"`
+
+	// Source puts the sales channel the order came from in the header, with Names mapping
+	// Shopify's source names (like web, pos or a marketplace app's) to what's printed
+	Source struct {
+		Show  bool              `yaml:"show"`
+		Names map[string]string `yaml:"names"`
+	} `yaml:"source"`
+
+	// Currency picks how amounts are written: style code like "12.50 USD", or symbol like "$12.50"
+	// (for the currencies with a symbol of their own). Either way they get the currency's decimal places.
+	Currency struct {
+		Style string `yaml:"style"`
+	} `yaml:"currency"`
+
+	Discounts struct {
+		ShowOrderDiscounts bool `yaml:"show-order-discounts"`
+		ShowLineDiscounts  bool `yaml:"show-line-discounts"`
+	} `yaml:"discounts"`
+
+	NoteAttributes struct {
+		Show bool     `yaml:"show"`
+		Keys []string `yaml:"keys"`
+	} `yaml:"note-attributes"`
+
+	// Pickup puts a PICKUP AT block in place of SHIP TO on local pickup orders when Show is set.
+	// The attributes name the note attributes some pickup apps put the location and a pickup code in.
+	Pickup struct {
+		Show              bool   `yaml:"show"`
+		LocationAttribute string `yaml:"location-attribute"`
+		CodeAttribute     string `yaml:"code-attribute"`
+		Instructions      string `yaml:"instructions"`
+	} `yaml:"pickup"`
+
+	Metafields struct {
+		Keys   []string          `yaml:"keys"`
+		Labels map[string]string `yaml:"labels"`
+	} `yaml:"metafields"`
+
+	// SectionConditions maps section names to the conditions an order has to pass for the section
+	// to be on its slip, like "tags has gift and total > 50"
+	SectionConditions map[string]string `yaml:"section-conditions"`
+}
+
+// RenderSlip writes a packing slip PDF for the order to w
+func RenderSlip(order goshopify.Order, cfg Config, w io.Writer) error {
+	_, err := RenderSlipLayout(order, cfg, w)
+	return err
+}
+
+// RenderSlips writes a single PDF to w with a page for each order, in order,
+// or Config.Copies.Total pages for each order if it's more than 1.
+// With Config.CutLine.Show, each page gets a dashed cut line along the bottom.
+// Each page is drawn just like a single slip, logo and header included,
+// and with fit enabled each page is shrunk on its own. With Config.Cover.Orders, the slips
+// come after a cover page listing those orders.
+func RenderSlips(orders []goshopify.Order, cfg Config, w io.Writer) error {
+	combined, err := renderCombined(orders, cfg)
+	if err != nil {
+		return err
+	}
+	return combined.Write(w)
+}
+
+// renderCombined renders the pages RenderSlips writes
+func renderCombined(orders []goshopify.Order, cfg Config) (*myPdf, error) {
+	if len(orders) == 0 {
+		return nil, errors.New("no orders to render")
+	}
+
+	first := max(cfg.Batch.Number, 1)
+	from, to := cfg.copyRange()
+
+	var combined *myPdf
+	if len(cfg.Cover.Orders) > 0 {
+		page, err := cfg.pageRect()
+		if err != nil {
+			return nil, err
+		}
+		combined, err = createPDF(page, fontSize, cfg.fontFiles())
+		if err != nil {
+			return nil, err
+		}
+		if err := combined.drawCover(cfg); err != nil {
+			return nil, err
+		}
+		combined.align = alignLeft
+	}
+	for i, order := range orders {
+		cfg.Batch.Number = first + i
+
+		for c := from; c <= to; c++ {
+			cfg.Copies.Number = c
+
+			// render the order by itself first to find the font size that fits it
+			p, err := renderFit(order, cfg)
+			if err != nil {
+				return nil, err
+			}
+			if combined == nil {
+				combined = p
+				continue
+			}
+
+			if cfg.CutLine.Show {
+				combined.drawCutLine(cfg)
+			}
+			combined.AddPage()
+			combined.fontSize = p.fontSize
+			combined.align = p.align
+			combined.maxLines = p.maxLines
+			combined.textWidth = p.textWidth
+			if err := combined.SetFont(fontStyleName[regular], "", combined.fontSize); err != nil {
+				return nil, err
+			}
+			combined.style = regular
+			if err := render(combined, order, cfg); err != nil {
+				return nil, err
+			}
+			// renderFit already logged them for this page
+			combined.warnings = nil
+		}
+	}
+
+	if cfg.CutLine.Show {
+		combined.drawCutLine(cfg)
+	}
+	if cfg.PDFMetadata.Enabled {
+		combined.setInfo(orders, cfg)
+	}
+	return combined, nil
+}
+
+// renderFit renders the order at the normal font size.
+// With fit enabled, it keeps rendering at smaller sizes until the content fits on the page
+// or the minimum size is reached.
+func renderFit(order goshopify.Order, cfg Config) (*myPdf, error) {
+	page, err := cfg.pageRect()
+	if err != nil {
+		return nil, err
+	}
+
+	align, err := cfg.textAlign()
+	if err != nil {
+		return nil, err
+	}
+
+	minSize := cfg.Fit.MinFontSize
+	if minSize <= 0 {
+		minSize = defaultMinFontSize
+	}
+
+	fonts := cfg.fontFiles()
+	size := float64(fontSize)
+	for {
+		// create the blank label
+		p, err := createPDF(page, size, fonts)
+		if err != nil {
+			return nil, err
+		}
+		p.align = align
+		p.maxLines = cfg.Text.MaxLines
+		p.textWidth = cfg.Text.Width
+
+		if err := render(p, order, cfg); err != nil {
+			return nil, err
+		}
+
+		// the warnings are only logged for the size that's kept, so they're logged once
+		if !p.overflowed() {
+			p.logWarnings()
+			warnMissingGlyphs(p, order, cfg)
+			if size < cfg.minReadableSize() {
+				Logger.Warn("Fit shrank the text below the min-readable-size", "order", order.Name, "size", size, "min-readable-size", cfg.minReadableSize())
+			}
+			return p, nil
+		}
+		if !cfg.Fit.Enabled {
+			p.logWarnings()
+			Logger.Warn("Content runs past the bottom of the page", "order", order.Name)
+			warnMissingGlyphs(p, order, cfg)
+			return p, nil
+		}
+		if size <= minSize {
+			p.logWarnings()
+			Logger.Warn("Content doesn't fit on the page even at the minimum font size", "order", order.Name, "size", size)
+			warnMissingGlyphs(p, order, cfg)
+			if size < cfg.minReadableSize() {
+				Logger.Warn("Fit shrank the text below the min-readable-size", "order", order.Name, "size", size, "min-readable-size", cfg.minReadableSize())
+			}
+			return p, nil
+		}
+		size = max(size-0.5, minSize)
+	}
+}
+
+// render draws the order onto the label, holding its warnings in p.warnings
+func render(p *myPdf, order goshopify.Order, cfg Config) error {
+	cfg.warnings = &p.warnings
+	cfg.Labels = cfg.Labels.withDefaults()
+	colors, err := cfg.colorScheme()
+	if err != nil {
+		return err
+	}
+	// monochrome slips are all black on white, which prints crisply on thermal printers
+	p.monochrome = cfg.Monochrome.Enabled
+	if p.monochrome {
+		colors = colorScheme{}
+	}
+	p.colors = colors
+
+	if err := p.drawBackground(cfg); err != nil {
+		return err
+	}
+	p.setTextColor(p.colors.text)
+
+	if cfg.Compact.Enabled {
+		p.SetXY(p.MarginLeft(), p.MarginTop())
+		return writeSections(p, order, cfg)
+	}
+
+	p.SetXY(p.MarginLeft(), float64(cfg.Logo.VerticalSpace))
+	x := p.GetX()
+	y := p.GetY()
+	rect, err := logoRect(cfg.Logo.Filename, cfg.Logo.Width)
+	if err != nil {
+		return err
+	}
+	err = p.drawImage(cfg.Logo.Filename, x, y, rect, cfg)
+	if err != nil {
+		return err
+	}
+	p.sections = append(p.sections, Section{Name: "logo", StartY: y, EndY: y + rect.H})
+
+	// without a text vertical-space, start the text just below the logo
+	textY := float64(cfg.Text.VerticalSpace)
+	if textY == 0 {
+		textY = y + rect.H + cfg.Logo.Gap
+	}
+	p.SetXY(p.MarginLeft(), textY)
+	if err := writeSections(p, order, cfg); err != nil {
+		return err
+	}
+
+	if cfg.Stamp.Filename != "" {
+		if err := p.drawStamp(cfg); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Hash.Show {
+		if err := p.writeHash(order); err != nil {
+			return err
+		}
+	}
+	return p.err
+}
+
+// warning is a warning for Logger, held until it's known whether the slip it's about is kept
+type warning struct {
+	msg     string
+	keyvals []interface{}
+}
+
+// warn logs a warning about the order, or holds it in the slip's warnings while a PDF is rendered
+func (cfg Config) warn(msg string, keyvals ...interface{}) {
+	if cfg.warnings == nil {
+		Logger.Warn(msg, keyvals...)
+		return
+	}
+	*cfg.warnings = append(*cfg.warnings, warning{msg: msg, keyvals: keyvals})
+}
+
+// warn holds a warning about the slip until it's logged
+func (p *myPdf) warn(msg string, keyvals ...interface{}) {
+	p.warnings = append(p.warnings, warning{msg: msg, keyvals: keyvals})
+}
+
+// logWarnings logs the warnings held for the slip
+func (p *myPdf) logWarnings() {
+	for _, w := range p.warnings {
+		Logger.Warn(w.msg, w.keyvals...)
+	}
+	p.warnings = nil
+}
+
+// warnMissingGlyphs warns about the characters on the slip that no font had, which come out blank
+func warnMissingGlyphs(p *myPdf, order goshopify.Order, cfg Config) {
+	if len(p.missingGlyphs) == 0 {
+		return
+	}
+	if cfg.Fonts.Fallback == "" {
+		Logger.Warn("The font doesn't have some characters, set fonts.fallback to draw them", "order", order.Name, "characters", p.missingGlyphList())
+		return
+	}
+	Logger.Warn("Neither the font nor the fallback font has some characters", "order", order.Name, "characters", p.missingGlyphList())
+}
+
+// copyRange returns the first and last copy numbers to render
+func (cfg Config) copyRange() (int, int) {
+	if cfg.Copies.Number > 0 {
+		return cfg.Copies.Number, cfg.Copies.Number
+	}
+	return 1, max(cfg.Copies.Total, 1)
+}
+
+// textAlign returns the alignment from the config, left if there isn't one
+func (cfg Config) textAlign() (alignment, error) {
+	if cfg.Text.Align == "" {
+		return alignLeft, nil
+	}
+	align, ok := alignmentNames[strings.ToLower(cfg.Text.Align)]
+	if !ok {
+		return alignLeft, fmt.Errorf("unknown text align %q (use left, center or right)", cfg.Text.Align)
+	}
+	return align, nil
+}
+
+// packSummary returns a line like "PACK: 7 items (4 SKUs)" for checking the total before packing.
+// Items without a SKU aren't counted as a SKU.
+func packSummary(lineItems []goshopify.LineItem, labels Labels) string {
+	total := 0
+	skus := map[string]bool{}
+	for _, lineItem := range lineItems {
+		total += lineItem.Quantity
+		if lineItem.SKU != "" {
+			skus[lineItem.SKU] = true
+		}
+	}
+	return fmt.Sprintf("%s %s (%s)", labels.Pack, plural(total, labels.SummaryItem, labels.SummaryItems), plural(len(skus), labels.SummarySKU, labels.SummarySKUs))
+}
+
+// plural returns the count and the singular noun if the count is 1, or the plural noun otherwise
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
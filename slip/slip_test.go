@@ -0,0 +1,140 @@
+package slip
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/charmbracelet/log"
+)
+
+// testOrder returns an order with the fields every slip uses, and the line items
+func testOrder(name string, lineItems ...goshopify.LineItem) goshopify.Order {
+	created := time.Date(2024, 5, 1, 15, 4, 5, 0, time.UTC)
+	return goshopify.Order{
+		Id:        1001,
+		Name:      name,
+		CreatedAt: &created,
+		ShippingAddress: &goshopify.Address{
+			FirstName: "Ada",
+			LastName:  "Lovelace",
+			Address1:  "1 Main St",
+			City:      "Springfield",
+			Country:   "United States",
+		},
+		LineItems: lineItems,
+	}
+}
+
+// testConfig returns a config with a small logo, which a PDF slip needs
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	fn := filepath.Join(t.TempDir(), "logo.png")
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 100, 40))); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Config
+	cfg.Logo.Filename = fn
+	return cfg
+}
+
+func TestRenderNoLineItems(t *testing.T) {
+	order := testOrder("#1001")
+	cfg := testConfig(t)
+
+	var text bytes.Buffer
+	if err := RenderTexts([]goshopify.Order{order}, cfg, &text); err != nil {
+		t.Fatalf("RenderTexts: %v", err)
+	}
+	if !strings.Contains(text.String(), defaultLabels.NoItems) {
+		t.Errorf("the slip doesn't say %q:\n%s", defaultLabels.NoItems, text.String())
+	}
+
+	var pdf bytes.Buffer
+	if err := RenderSlip(order, cfg, &pdf); err != nil {
+		t.Fatalf("RenderSlip: %v", err)
+	}
+	if !bytes.HasPrefix(pdf.Bytes(), []byte("%PDF")) {
+		t.Error("RenderSlip didn't write a PDF")
+	}
+}
+
+func TestRenderCombinedPages(t *testing.T) {
+	var orders []goshopify.Order
+	for i, name := range []string{"#1001", "#1002", "#1003"} {
+		order := testOrder(name, goshopify.LineItem{Id: uint64(i + 1), Name: "Mug", Quantity: 1})
+		order.Id = uint64(1001 + i)
+		orders = append(orders, order)
+	}
+
+	p, err := renderCombined(orders, testConfig(t))
+	if err != nil {
+		t.Fatalf("renderCombined: %v", err)
+	}
+	if n := p.GetNumberOfPages(); n != len(orders) {
+		t.Errorf("got %d pages, want %d", n, len(orders))
+	}
+	// each page is drawn like a slip of its own, so each one has the logo and the header
+	counts := map[string]int{}
+	for _, s := range p.sections {
+		counts[s.Name]++
+	}
+	for _, name := range []string{"logo", "header"} {
+		if counts[name] != len(orders) {
+			t.Errorf("got the %s on %d pages, want all %d", name, counts[name], len(orders))
+		}
+	}
+}
+
+func TestRenderCombinedWarnsOnce(t *testing.T) {
+	var orders []goshopify.Order
+	for i, name := range []string{"#1001", "#1002", "#1003"} {
+		order := testOrder(name)
+		order.Id = uint64(1001 + i)
+		orders = append(orders, order)
+	}
+
+	var logged bytes.Buffer
+	defer func(l *log.Logger) { Logger = l }(Logger)
+	Logger = log.New(&logged)
+
+	if _, err := renderCombined(orders, testConfig(t)); err != nil {
+		t.Fatalf("renderCombined: %v", err)
+	}
+	// each page is rendered twice, once to fit it and once onto the combined PDF
+	if n := strings.Count(logged.String(), "Order has no line items"); n != len(orders) {
+		t.Errorf("got %d warnings, want one for each of the %d orders:\n%s", n, len(orders), logged.String())
+	}
+}
+
+func TestRenderNoDate(t *testing.T) {
+	order := testOrder("#1001", goshopify.LineItem{Id: 1, Name: "Mug", Quantity: 1})
+	order.CreatedAt = nil
+	cfg := testConfig(t)
+
+	var text bytes.Buffer
+	if err := RenderTexts([]goshopify.Order{order}, cfg, &text); err != nil {
+		t.Fatalf("RenderTexts: %v", err)
+	}
+	if !strings.Contains(text.String(), "#1001") {
+		t.Errorf("the slip doesn't have the order name:\n%s", text.String())
+	}
+
+	var pdf bytes.Buffer
+	if err := RenderSlip(order, cfg, &pdf); err != nil {
+		t.Fatalf("RenderSlip: %v", err)
+	}
+}
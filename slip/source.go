@@ -0,0 +1,30 @@
+package slip
+
+import goshopify "github.com/bold-commerce/go-shopify/v4"
+
+// defaultSourceNames are the names printed for Shopify's own source names. The names in
+// the config's source section are added to them, or replace them.
+var defaultSourceNames = map[string]string{
+	"web":                 "Online Store",
+	"pos":                 "Point of Sale",
+	"shopify_draft_order": "Draft Order",
+	"iphone":              "Shopify Mobile",
+	"android":             "Shopify Mobile",
+}
+
+// sourceLine returns the header's line about the sales channel the order came from, like
+// "Source: Online Store", or nothing if it isn't shown or Shopify doesn't say.
+// A source name without a friendly name is printed as it is.
+func (cfg Config) sourceLine(order goshopify.Order) string {
+	if !cfg.Source.Show || order.SourceName == "" {
+		return ""
+	}
+	name, ok := cfg.Source.Names[order.SourceName]
+	if !ok {
+		name, ok = defaultSourceNames[order.SourceName]
+	}
+	if !ok {
+		name = order.SourceName
+	}
+	return cfg.Labels.Source + " " + name
+}
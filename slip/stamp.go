@@ -0,0 +1,56 @@
+package slip
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// drawStamp draws the stamp image at the cursor, scaled like the logo and placed by its align,
+// then moves the cursor below it. A missing stamp file only gets a warning.
+func (p *myPdf) drawStamp(cfg Config) error {
+	rect, err := logoRect(cfg.Stamp.Filename, cfg.Stamp.Width)
+	if errors.Is(err, fs.ErrNotExist) {
+		p.warn("Stamp file not found, skipping it", "file", cfg.Stamp.Filename)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	align, err := cfg.stampAlign()
+	if err != nil {
+		return err
+	}
+
+	left := p.MarginLeft()
+	space := p.page.W - p.MarginRight() - left
+	x := left
+	switch align {
+	case alignCenter:
+		x += max(space-rect.W, 0) / 2
+	case alignRight:
+		x += max(space-rect.W, 0)
+	}
+
+	y := p.GetY()
+	if err := p.drawImage(cfg.Stamp.Filename, x, y, rect, cfg); err != nil {
+		return err
+	}
+	p.SetXY(left, y+rect.H)
+	p.markSection("stamp", y)
+	return nil
+}
+
+// stampAlign returns the alignment of the stamp, left if there isn't one
+func (cfg Config) stampAlign() (alignment, error) {
+	if cfg.Stamp.Align == "" {
+		return alignLeft, nil
+	}
+	align, ok := alignmentNames[strings.ToLower(cfg.Stamp.Align)]
+	if !ok {
+		return alignLeft, fmt.Errorf("unknown stamp align %q (use left, center or right)", cfg.Stamp.Align)
+	}
+	return align, nil
+}
@@ -0,0 +1,192 @@
+package slip
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// fontFiles are the TrueType files the slip is drawn with.
+// An empty regular and bold mean the embedded Arial Rounded.
+type fontFiles struct {
+	regular, bold, fallback string
+}
+
+// fontFiles returns the files of the fonts.by-name family if it's installed, and the fallback font
+func (cfg Config) fontFiles() fontFiles {
+	files := fontFiles{fallback: cfg.Fonts.Fallback}
+	if cfg.Fonts.ByName != "" {
+		files.regular, files.bold = lookupSystemFont(cfg.Fonts.ByName)
+	}
+	return files
+}
+
+// systemFonts holds the regular and bold files found for each family, so the font directories
+// are only searched once. Families that aren't installed are kept too, with no files.
+// Slips can be rendered at the same time, so it's only used with systemFontsMu held.
+var (
+	systemFonts   = map[string][2]string{}
+	systemFontsMu sync.Mutex
+)
+
+// lookupSystemFont returns the regular and bold TrueType files of an installed font family,
+// or no files (with a warning) if it isn't installed. A family without a bold style uses the
+// regular one for both.
+func lookupSystemFont(family string) (regular, bold string) {
+	key := strings.ToLower(family)
+	// held while the directories are searched, so slips rendered at once only search them once
+	systemFontsMu.Lock()
+	defer systemFontsMu.Unlock()
+	if files, ok := systemFonts[key]; ok {
+		return files[0], files[1]
+	}
+
+	for _, dir := range systemFontDirs() {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".ttf") {
+				return nil
+			}
+			name, style, err := fontNames(path)
+			if err != nil || !strings.EqualFold(name, family) {
+				return nil
+			}
+			switch strings.ToLower(style) {
+			case "regular", "book", "normal", "roman", "":
+				if regular == "" {
+					regular = path
+				}
+			case "bold":
+				if bold == "" {
+					bold = path
+				}
+			}
+			return nil
+		})
+	}
+
+	switch {
+	case regular == "":
+		Logger.Warn("The fonts.by-name font isn't installed (as a .ttf file), using the embedded font", "font", family)
+		bold = ""
+	case bold == "":
+		Logger.Warn("The fonts.by-name font has no bold style, using the regular style for bold text too", "font", family)
+		bold = regular
+	}
+	systemFonts[key] = [2]string{regular, bold}
+	return regular, bold
+}
+
+// systemFontDirs returns the directories fonts are installed in on this OS, for everyone and for the user
+func systemFontDirs() []string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "Fonts"), "/Library/Fonts", "/System/Library/Fonts"}
+	case "windows":
+		dirs := []string{filepath.Join(os.Getenv("WINDIR"), "Fonts")}
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			dirs = append(dirs, filepath.Join(local, "Microsoft", "Windows", "Fonts"))
+		}
+		return dirs
+	default:
+		dirs := []string{filepath.Join(home, ".local", "share", "fonts"), filepath.Join(home, ".fonts")}
+		if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+			dirs = append(dirs, filepath.Join(dataHome, "fonts"))
+		}
+		return append(dirs, "/usr/local/share/fonts", "/usr/share/fonts")
+	}
+}
+
+// name table IDs of the family and style names. The typographic ones, where a font has them,
+// keep weights like Light in the style instead of the family.
+const (
+	nameFamily            = 1
+	nameSubfamily         = 2
+	nameTypographicFamily = 16
+	nameTypographicSub    = 17
+)
+
+// fontNames reads the family and style (like "Bold") names from the name table of a TrueType file.
+// Only the table directory and the name table are read, since font files can be large.
+func fontNames(fn string) (family, style string, err error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", "", err
+	}
+
+	header := make([]byte, 12)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return "", "", err
+	}
+	numTables := int(binary.BigEndian.Uint16(header[4:]))
+	records := make([]byte, 16*numTables)
+	if _, err := f.ReadAt(records, 12); err != nil {
+		return "", "", err
+	}
+
+	var table []byte
+	for i := 0; i < numTables; i++ {
+		record := records[16*i:]
+		if string(record[:4]) != "name" {
+			continue
+		}
+		// a corrupt file can claim any length, so it's checked against the file before it's read
+		offset, length := int64(binary.BigEndian.Uint32(record[8:])), int64(binary.BigEndian.Uint32(record[12:]))
+		if offset+length > info.Size() {
+			return "", "", errors.New("the name table runs past the end of the file")
+		}
+		table = make([]byte, length)
+		if _, err := f.ReadAt(table, offset); err != nil {
+			return "", "", err
+		}
+		break
+	}
+	if len(table) < 6 {
+		return "", "", errors.New("no name table")
+	}
+
+	names := map[uint16]string{}
+	count := int(binary.BigEndian.Uint16(table[2:]))
+	stringsStart := int(binary.BigEndian.Uint16(table[4:]))
+	for i := 0; i < count && 6+12*(i+1) <= len(table); i++ {
+		record := table[6+12*i:]
+		platform := binary.BigEndian.Uint16(record)
+		id := binary.BigEndian.Uint16(record[6:])
+		length := int(binary.BigEndian.Uint16(record[8:]))
+		start := stringsStart + int(binary.BigEndian.Uint16(record[10:]))
+		if start+length > len(table) {
+			continue
+		}
+		raw := table[start : start+length]
+
+		switch platform {
+		case 3: // Windows, UTF-16 big endian, preferred over the Mac names
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = binary.BigEndian.Uint16(raw[2*j:])
+			}
+			names[id] = string(utf16.Decode(units))
+		case 1: // Mac, which is ASCII for the names that matter here
+			if _, ok := names[id]; !ok {
+				names[id] = string(raw)
+			}
+		}
+	}
+
+	family, style = names[nameFamily], names[nameSubfamily]
+	if names[nameTypographicFamily] != "" {
+		family, style = names[nameTypographicFamily], names[nameTypographicSub]
+	}
+	return family, style, nil
+}
@@ -0,0 +1,190 @@
+package slip
+
+import (
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// tableRow is one row of the item table: the quantity, the name and the SKU.
+// A header without a SKU heading has no SKU column.
+type tableRow [3]string
+
+// writeItemTable writes the line items as a table with Qty, Name and SKU columns.
+// The name wraps within its column, with the vendor after it when show-vendor is on.
+// The SKU column follows show-sku: it's left out with never, or by default if none of the items have one.
+func writeItemTable(w slipWriter, lineItems []goshopify.LineItem, cfg Config) {
+	header := tableRow{cfg.Items.QuantityLabel, cfg.Labels.Item, strings.TrimSuffix(cfg.Labels.SKU, ":")}
+	if header[0] == "" {
+		header[0] = cfg.Labels.Quantity
+	}
+
+	rows := make([]tableRow, len(lineItems))
+	hasSKU := false
+	for i, lineItem := range lineItems {
+		name := lineItem.Name + cfg.refundedNote(lineItem)
+		if cfg.Items.ShowVendor && lineItem.Vendor != "" {
+			name += " " + cfg.Labels.Vendor + " " + lineItem.Vendor
+		}
+		if line := cfg.backorderLine(lineItem); line != "" {
+			name += " " + line
+		}
+		sku := ""
+		if showSKU(cfg.Items.ShowSKU, lineItem.SKU) {
+			sku = lineItem.SKU
+			hasSKU = true
+		}
+		rows[i] = tableRow{cfg.quantity(lineItem), name, sku}
+	}
+	if !hasSKU {
+		header[2] = ""
+	}
+
+	maxLines := cfg.Items.MaxNameLines
+	if maxLines == 0 {
+		maxLines = cfg.Text.MaxLines
+	}
+	w.writeTable(header, rows, maxLines)
+}
+
+// columnWidths splits the total width into the quantity, name and SKU columns.
+// The quantity and SKU columns are as wide as their widest cell (the SKU column no more than
+// a third of the total), with a gap after each, and the name gets the rest.
+// Without a SKU heading, the SKU column has no width.
+func columnWidths(header tableRow, rows []tableRow, total, gap float64, measure func(s string, heading bool) float64) (qty, name, sku float64) {
+	qty = measure(header[0], true)
+	for _, row := range rows {
+		qty = max(qty, measure(row[0], false))
+		if row[2] != "" {
+			sku = max(sku, measure(row[2], false))
+		}
+	}
+	if header[2] != "" {
+		sku = min(max(sku, measure(header[2], true)), total/3)
+		name = total - qty - sku - 2*gap
+	} else {
+		name = total - qty - gap
+	}
+	return qty, name, sku
+}
+
+// writeTable draws the item table between the margins (or in the text width), with the quantities right aligned
+func (p *myPdf) writeTable(header tableRow, rows []tableRow, maxNameLines int) {
+	left := p.MarginLeft()
+	total := p.textSpace()
+	gap := p.fontSize / 2
+
+	measure := func(s string, heading bool) float64 {
+		if heading {
+			p.changeFontStyle(bold)
+			defer p.changeFontStyle(regular)
+		}
+		width, err := p.runsWidth(p.fontRuns(s))
+		if err != nil {
+			return 0
+		}
+		return width
+	}
+	qtyW, nameW, skuW := columnWidths(header, rows, total, gap, measure)
+	nameX := left + qtyW + gap
+	skuX := nameX + nameW + gap
+
+	writeRow := func(row tableRow, style fontStyle) {
+		p.changeFontStyle(style)
+		y := p.GetY()
+
+		qty := p.fontRuns(row[0])
+		if width, err := p.runsWidth(qty); err == nil {
+			p.SetXY(left+qtyW-width, y)
+		}
+		_ = p.cellRuns(qty)
+
+		if skuW > 0 && row[2] != "" {
+			p.SetXY(skuX, y)
+			_ = p.cellRuns(p.fontRuns(p.fitText(row[2], skuW)))
+		}
+
+		lines, err := p.SplitTextWithWordWrap(row[1], nameW)
+		if err != nil {
+			lines = []string{row[1]}
+		}
+		if maxNameLines > 0 && len(lines) > maxNameLines {
+			lines = lines[:maxNameLines]
+			lines[maxNameLines-1] = truncateLine(lines[maxNameLines-1], func(t string) bool {
+				w, err := p.MeasureTextWidth(t)
+				return err == nil && w <= nameW
+			})
+		}
+		for i, line := range lines {
+			p.SetXY(nameX, y+float64(i)*p.lineHeight())
+			_ = p.cellRuns(p.fontRuns(line))
+		}
+		p.SetXY(left, y+float64(max(len(lines), 1))*p.lineHeight())
+	}
+
+	p.setTextColor(p.colors.heading)
+	writeRow(header, bold)
+	p.setTextColor(p.colors.text)
+	for _, row := range rows {
+		writeRow(row, regular)
+	}
+	p.changeFontStyle(regular)
+	p.Br(p.lineHeight())
+}
+
+// fitText cuts the text off with an ellipsis if it's wider than width
+func (p *myPdf) fitText(s string, width float64) string {
+	fits := func(t string) bool {
+		w, err := p.MeasureTextWidth(t)
+		return err == nil && w <= width
+	}
+	if fits(s) {
+		return s
+	}
+	return truncateLine(s, fits)
+}
+
+// writeTable writes the item table in columns of characters, with the quantities right aligned
+func (t *textSlip) writeTable(header tableRow, rows []tableRow, maxNameLines int) {
+	measure := func(s string, heading bool) float64 {
+		return float64(len([]rune(s)))
+	}
+	qtyF, nameF, skuF := columnWidths(header, rows, float64(t.width), 1, measure)
+	qtyW, nameW, skuW := int(qtyF), int(nameF), int(skuF)
+
+	writeRow := func(row tableRow) {
+		lines := wrapText(row[1], max(nameW, 1))
+		if len(lines) == 0 {
+			lines = []string{""}
+		}
+		if maxNameLines > 0 && len(lines) > maxNameLines {
+			lines = lines[:maxNameLines]
+			lines[maxNameLines-1] = truncateLine(lines[maxNameLines-1], func(s string) bool {
+				return len([]rune(s)) <= nameW
+			})
+		}
+
+		sku := row[2]
+		if len([]rune(sku)) > skuW {
+			sku = truncateLine(sku, func(s string) bool { return len([]rune(s)) <= skuW })
+		}
+
+		for i, line := range lines {
+			qty := ""
+			if i == 0 {
+				qty = row[0]
+			}
+			text := strings.Repeat(" ", max(qtyW-len([]rune(qty)), 0)) + qty + " " + line
+			if i == 0 && skuW > 0 && sku != "" {
+				text += strings.Repeat(" ", max(nameW-len([]rune(line)), 0)) + " " + sku
+			}
+			t.b.WriteString(strings.TrimRight(text, " ") + "\n")
+		}
+	}
+
+	writeRow(header)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	t.b.WriteString("\n")
+}
@@ -0,0 +1,59 @@
+package slip
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+func TestTableSKU(t *testing.T) {
+	tests := []struct {
+		name       string
+		showSKU    string
+		sku        string
+		wantColumn bool
+	}{
+		{name: "default", sku: "MUG-1", wantColumn: true},
+		{name: "default blank", sku: "", wantColumn: false},
+		{name: "never", showSKU: "never", sku: "MUG-1", wantColumn: false},
+		{name: "always", showSKU: "always", sku: "MUG-1", wantColumn: true},
+		{name: "always blank", showSKU: "always", sku: "", wantColumn: true},
+	}
+	heading := strings.TrimSuffix(defaultLabels.SKU, ":")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testOrder("#1001", goshopify.LineItem{Id: 1, Name: "Mug", Quantity: 1, SKU: tt.sku})
+			var cfg Config
+			cfg.Items.Layout = "table"
+			cfg.Items.ShowSKU = tt.showSKU
+			var b bytes.Buffer
+			if err := RenderTexts([]goshopify.Order{order}, cfg, &b); err != nil {
+				t.Fatalf("RenderTexts: %v", err)
+			}
+			if got := strings.Contains(b.String(), heading); got != tt.wantColumn {
+				t.Errorf("SKU column: %v, want %v:\n%s", got, tt.wantColumn, b.String())
+			}
+			if tt.sku != "" && strings.Contains(b.String(), tt.sku) != tt.wantColumn {
+				t.Errorf("SKU %q printed: %v, want %v:\n%s", tt.sku, !tt.wantColumn, tt.wantColumn, b.String())
+			}
+		})
+	}
+}
+
+func TestTableVendor(t *testing.T) {
+	order := testOrder("#1001", goshopify.LineItem{Id: 1, Name: "Mug", Quantity: 1, Vendor: "Acme"})
+	for _, showVendor := range []bool{false, true} {
+		var cfg Config
+		cfg.Items.Layout = "table"
+		cfg.Items.ShowVendor = showVendor
+		var b bytes.Buffer
+		if err := RenderTexts([]goshopify.Order{order}, cfg, &b); err != nil {
+			t.Fatalf("RenderTexts: %v", err)
+		}
+		if got := strings.Contains(b.String(), "Acme"); got != showVendor {
+			t.Errorf("show-vendor %v printed the vendor: %v:\n%s", showVendor, got, b.String())
+		}
+	}
+}
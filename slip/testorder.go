@@ -0,0 +1,31 @@
+package slip
+
+import (
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// IsTestOrder reports whether the order was a test: marked as one by Shopify,
+// or paid for with the Bogus Gateway that test checkouts use
+func IsTestOrder(order goshopify.Order) bool {
+	if order.Test || isBogusGateway(order.Gateway) {
+		return true
+	}
+	for _, name := range order.PaymentGatewayNames {
+		if isBogusGateway(name) {
+			return true
+		}
+	}
+	for _, t := range order.Transactions {
+		if t.Test || isBogusGateway(t.Gateway) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBogusGateway reports whether a gateway name is Shopify's Bogus Gateway, which shows up as "bogus"
+func isBogusGateway(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), "bogus")
+}
@@ -0,0 +1,151 @@
+package slip
+
+import (
+	"io"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// the number of columns a plain text slip wraps at unless the config says otherwise,
+// which suits an 80mm receipt printer
+const defaultTextWidth = 42
+
+// textSlip writes a slip as plain text, for printers that take raw text
+type textSlip struct {
+	b        strings.Builder
+	width    int
+	align    alignment
+	maxLines int
+}
+
+// RenderText writes a plain text packing slip for the order to w.
+// It has the same sections as the PDF, without the logo, background or watermark.
+func RenderText(order goshopify.Order, cfg Config, w io.Writer) error {
+	return RenderTexts([]goshopify.Order{order}, cfg, w)
+}
+
+// RenderTexts writes plain text packing slips for the orders to w, one after the other
+// (and Config.Copies.Total of each if it's more than 1), with a dashed line between them
+func RenderTexts(orders []goshopify.Order, cfg Config, w io.Writer) error {
+	align, err := cfg.textAlign()
+	if err != nil {
+		return err
+	}
+	cfg.Labels = cfg.Labels.withDefaults()
+
+	t := &textSlip{width: cfg.PlainText.Width, align: align, maxLines: cfg.Text.MaxLines}
+	if t.width <= 0 {
+		t.width = defaultTextWidth
+	}
+
+	first := max(cfg.Batch.Number, 1)
+	from, to := cfg.copyRange()
+	for i, order := range orders {
+		cfg.Batch.Number = first + i
+		for c := from; c <= to; c++ {
+			if i > 0 || c > from {
+				t.b.WriteString("\n" + strings.Repeat("-", t.width) + "\n\n")
+			}
+
+			cfg.Copies.Number = c
+			if err := writeSections(t, order, cfg); err != nil {
+				return err
+			}
+			if cfg.Hash.Show {
+				t.writeLine("\n" + orderHash(order))
+			}
+		}
+	}
+
+	_, err = io.WriteString(w, t.b.String())
+	return err
+}
+
+// writeLine writes a line wrapped at the width.
+// Like the PDF version, more than 1 trailing newline adds blank lines.
+func (t *textSlip) writeLine(s string) {
+	t.writeLineMax(s, t.maxLines)
+}
+
+// writeLineMax writes a line like writeLine, cut off with an ellipsis after maxLines wrapped lines
+func (t *textSlip) writeLineMax(s string, maxLines int) {
+	trimmed := strings.TrimRight(s, "\n")
+	newlines := len(s) - len(trimmed)
+
+	if trimmed != "" {
+		var texts []string
+		for _, line := range strings.Split(trimmed, "\n") {
+			texts = append(texts, wrapText(line, t.width)...)
+		}
+		if maxLines > 0 && len(texts) > maxLines {
+			texts = texts[:maxLines]
+			texts[maxLines-1] = truncateLine(texts[maxLines-1], func(s string) bool {
+				return len([]rune(s)) <= t.width
+			})
+		}
+		for _, text := range texts {
+			t.b.WriteString(t.pad(text) + text + "\n")
+		}
+	}
+
+	if newlines > 1 {
+		t.b.WriteString(strings.Repeat("\n", newlines-1))
+	}
+}
+
+// pad returns the spaces that put a line in place for the alignment
+func (t *textSlip) pad(text string) string {
+	space := max(t.width-len([]rune(text)), 0)
+	switch t.align {
+	case alignCenter:
+		return strings.Repeat(" ", space/2)
+	case alignRight:
+		return strings.Repeat(" ", space)
+	}
+	return ""
+}
+
+// changeFontStyle does nothing, since plain text doesn't have bold
+func (t *textSlip) changeFontStyle(s fontStyle) {}
+
+// section writes a section. Plain text slips don't keep a layout.
+func (t *textSlip) section(name string, write func() error) error {
+	return write()
+}
+
+// region writes a section. Plain text slips have no fixed heights, so it just follows on.
+func (t *textSlip) region(name string, height float64, write func() error) error {
+	return write()
+}
+
+// wrapText splits a line into lines of at most width runes, breaking between words where it can
+func wrapText(s string, width int) []string {
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(s) {
+		w := []rune(word)
+		// words longer than a whole line get split wherever they reach the end
+		for len(w) > width {
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		switch {
+		case len(line) == 0:
+			line = w
+		case len(line)+1+len(w) <= width:
+			line = append(append(line, ' '), w...)
+		default:
+			lines = append(lines, string(line))
+			line = w
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
@@ -0,0 +1,160 @@
+package slip
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Validate checks the config for values that can't be rendered,
+// so a mistake is reported before anything is fetched or drawn
+func (cfg Config) Validate() error {
+	if _, err := cfg.pageRect(); err != nil {
+		return err
+	}
+	if cfg.Page.Width < 0 || cfg.Page.Height < 0 {
+		return fmt.Errorf("page width and height can't be negative")
+	}
+
+	if cfg.Page.Background != "" {
+		if _, _, _, err := parseColor(cfg.Page.Background); err != nil {
+			return err
+		}
+	}
+
+	if _, err := cfg.colorScheme(); err != nil {
+		return err
+	}
+
+	// compact slips have no logo
+	if cfg.Logo.Filename == "" && !cfg.Compact.Enabled {
+		return fmt.Errorf("logo filename is required")
+	}
+	if _, err := os.Stat(cfg.Logo.Filename); err != nil && !cfg.Compact.Enabled {
+		return fmt.Errorf("logo %s: %w", cfg.Logo.Filename, err)
+	}
+	for _, field := range cfg.Compact.Fields {
+		if !slices.Contains(compactFieldNames, field) {
+			return fmt.Errorf("unknown compact field %q (use %s)", field, strings.Join(compactFieldNames, ", "))
+		}
+	}
+	if cfg.Images.MaxPx < 0 {
+		return fmt.Errorf("images max-px can't be negative")
+	}
+	if cfg.Logo.Width < 0 {
+		return fmt.Errorf("logo width can't be negative")
+	}
+	if cfg.Logo.VerticalSpace < 0 || cfg.Logo.Gap < 0 {
+		return fmt.Errorf("logo vertical-space and gap can't be negative")
+	}
+
+	// a missing stamp is only a warning when rendering, so it isn't checked here
+	if cfg.Stamp.Width < 0 {
+		return fmt.Errorf("stamp width can't be negative")
+	}
+	if _, err := cfg.stampAlign(); err != nil {
+		return err
+	}
+
+	if cfg.Fonts.Fallback != "" {
+		if _, err := os.Stat(cfg.Fonts.Fallback); err != nil {
+			return fmt.Errorf("fallback font %s: %w", cfg.Fonts.Fallback, err)
+		}
+	}
+
+	if cfg.CutLine.Dash < 0 || cfg.CutLine.Gap < 0 {
+		return fmt.Errorf("cut-line dash and gap can't be negative")
+	}
+
+	if cfg.Text.VerticalSpace < 0 {
+		return fmt.Errorf("text vertical-space can't be negative")
+	}
+	if cfg.Text.HeaderHeight < 0 {
+		return fmt.Errorf("text header-height can't be negative")
+	}
+	if cfg.Text.Width < 0 {
+		return fmt.Errorf("text width can't be negative")
+	}
+	if page, _ := cfg.pageRect(); cfg.Text.Width > page.W-2*pageMargin {
+		return fmt.Errorf("text width %g is wider than the %g points between the margins", cfg.Text.Width, page.W-2*pageMargin)
+	}
+	if cfg.Text.MaxLines < 0 || cfg.Items.MaxNameLines < 0 {
+		return fmt.Errorf("text max-lines and items max-name-lines can't be negative")
+	}
+	if _, err := cfg.textAlign(); err != nil {
+		return err
+	}
+	if cfg.Text.ItemTemplate != "" {
+		if _, err := template.New("item").Parse(cfg.Text.ItemTemplate); err != nil {
+			return fmt.Errorf("failed to parse item-template: %w", err)
+		}
+	}
+	switch cfg.Items.Layout {
+	case "", "list":
+	case "table":
+		if cfg.Text.ItemTemplate != "" {
+			return fmt.Errorf("items layout table can't be used with an item-template")
+		}
+	default:
+		return fmt.Errorf("unknown items layout %q (use list or table)", cfg.Items.Layout)
+	}
+	if cfg.Text.HeaderTemplate != "" {
+		if _, err := template.New("header").Parse(cfg.Text.HeaderTemplate); err != nil {
+			return fmt.Errorf("failed to parse header-template: %w", err)
+		}
+	}
+	if cfg.Text.AddressTemplate != "" {
+		if _, err := template.New("address").Parse(cfg.Text.AddressTemplate); err != nil {
+			return fmt.Errorf("failed to parse address-template: %w", err)
+		}
+	}
+
+	switch cfg.Items.Emoji {
+	case "", "keep", "strip", "placeholder":
+	default:
+		return fmt.Errorf("unknown items emoji %q (use keep, strip or placeholder)", cfg.Items.Emoji)
+	}
+	if cfg.Items.Backorder.MinShort < 0 {
+		return fmt.Errorf("items backorder min-short can't be negative")
+	}
+	switch cfg.Currency.Style {
+	case "", "code", "symbol":
+	default:
+		return fmt.Errorf("unknown currency style %q (use code or symbol)", cfg.Currency.Style)
+	}
+	if cfg.Monochrome.Threshold < 0 || cfg.Monochrome.Threshold > 255 {
+		return fmt.Errorf("monochrome threshold has to be between 0 and 255")
+	}
+	if cfg.Text.MinReadableSize < 0 {
+		return fmt.Errorf("text min-readable-size can't be negative")
+	}
+	if cfg.Fit.MinFontSize < 0 || cfg.Fit.MinFontSize > fontSize {
+		return fmt.Errorf("fit min-font-size must be between 0 and %d", fontSize)
+	}
+
+	switch cfg.Items.ShowSKU {
+	case "", "auto", "always", "never":
+	default:
+		return fmt.Errorf("unknown items show-sku %q (use auto, always or never)", cfg.Items.ShowSKU)
+	}
+	if cfg.Items.QuantityFormat != "" && strings.Contains(fmt.Sprintf(cfg.Items.QuantityFormat, 1.5), "%!") {
+		return fmt.Errorf("items quantity-format %q needs one float verb, like %%g or %%.2f", cfg.Items.QuantityFormat)
+	}
+
+	if _, err := sortLineItems(nil, cfg.Items.Sort, cfg.Items.Locations); err != nil {
+		return err
+	}
+	if cfg.PlainText.Width < 0 {
+		return fmt.Errorf("plain-text width can't be negative")
+	}
+
+	for _, key := range cfg.Metafields.Keys {
+		namespace, name, ok := strings.Cut(key, ".")
+		if !ok || namespace == "" || name == "" {
+			return fmt.Errorf("metafield %q should look like NAMESPACE.KEY", key)
+		}
+	}
+	return cfg.checkSectionConditions()
+}
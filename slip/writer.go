@@ -0,0 +1,331 @@
+package slip
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+)
+
+// slipWriter is what the sections of a slip are written to,
+// so the PDF and plain text slips share the same layout
+type slipWriter interface {
+	writeLine(s string)
+	// writeLineMax is writeLine cut off with an ellipsis after maxLines wrapped lines, if maxLines isn't 0
+	writeLineMax(s string, maxLines int)
+	changeFontStyle(s fontStyle)
+	// writeHeading writes a bold heading and a blank line, in the heading color where there are colors
+	writeHeading(s string)
+	// banner writes a line of text that stands out, on a bar of the color where there are colors
+	banner(text string, color [3]uint8)
+	// barcode writes the text as a barcode, where barcodes can be drawn
+	barcode(text string)
+	// writeTable writes the item table, with the name column cut off after maxNameLines wrapped lines if it isn't 0
+	writeTable(header tableRow, rows []tableRow, maxNameLines int)
+	// section calls write and records what it wrote as the named section
+	section(name string, write func() error) error
+	// region is section for an area of a fixed height, which continues below it
+	// however much write wrote. A height of 0 is just section.
+	region(name string, height float64, write func() error) error
+}
+
+// writeSections writes the text of the slip, from the header to the signature.
+// Sections with section-conditions are only written for the orders that pass them.
+func writeSections(w slipWriter, order goshopify.Order, cfg Config) error {
+	if cfg.Items.Backorder.Show {
+		cfg.settled = settledQuantities(order, !cfg.Items.NetQuantities)
+	}
+	if cfg.Items.NetQuantities {
+		cfg.refunded = refundedQuantities(order)
+		order = netQuantities(order, cfg.refunded)
+	}
+	order = cfg.withoutEmoji(order)
+	if len(cfg.SectionConditions) > 0 {
+		w = conditionalWriter{slipWriter: w, order: order, cfg: cfg}
+	}
+	if cfg.Compact.Enabled {
+		return writeCompact(w, order, cfg)
+	}
+
+	var headerTemplate *template.Template
+	if cfg.Text.HeaderTemplate != "" {
+		var err error
+		headerTemplate, err = template.New("header").Parse(cfg.Text.HeaderTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to parse header-template: %w", err)
+		}
+	}
+	var addressTemplate *template.Template
+	if cfg.Text.AddressTemplate != "" {
+		var err error
+		addressTemplate, err = template.New("address").Parse(cfg.Text.AddressTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to parse address-template: %w", err)
+		}
+	}
+
+	// test orders get a banner above everything else, so nobody packs them by mistake
+	if IsTestOrder(order) {
+		cfg.warn("Order is a test order", "order", order.Name)
+		err := w.section("test order", func() error {
+			w.changeFontStyle(bold)
+			w.writeLine(cfg.Labels.TestOrder + "\n\n")
+			w.changeFontStyle(regular)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Risk.Show {
+		err := w.section("risk", func() error {
+			writeRisk(w, order, cfg)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err := w.region("header", cfg.Text.HeaderHeight, func() error {
+		if headerTemplate != nil {
+			return writeHeaderTemplate(w, headerTemplate, order)
+		}
+		w.writeLine(cfg.Labels.Order + " " + order.Name)
+
+		// the estimated delivery and the source go under the date, and the batch and copy numbers in bold under that
+		var counts []string
+		if cfg.Batch.Total > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d %s %d", cfg.Labels.Slip, max(cfg.Batch.Number, 1), cfg.Labels.Of, cfg.Batch.Total))
+		}
+		if cfg.Copies.Label && cfg.Copies.Total > 1 {
+			counts = append(counts, fmt.Sprintf("%s %d %s %d", cfg.Labels.Copy, max(cfg.Copies.Number, 1), cfg.Labels.Of, cfg.Copies.Total))
+		}
+		// an order without a date, like one built by hand, just leaves the date out
+		var lines []string
+		if order.CreatedAt != nil {
+			lines = append(lines, order.CreatedAt.Format(cfg.dateFormat()))
+		}
+		if delivery := cfg.deliveryLine(order); delivery != "" {
+			lines = append(lines, delivery)
+		}
+		if source := cfg.sourceLine(order); source != "" {
+			lines = append(lines, source)
+		}
+		if len(counts) == 0 {
+			w.writeLine(strings.Join(lines, "\n") + "\n\n")
+			return nil
+		}
+		if len(lines) > 0 {
+			w.writeLine(strings.Join(lines, "\n"))
+		}
+		w.changeFontStyle(bold)
+		w.writeLine(strings.Join(counts, "\n") + "\n\n")
+		w.changeFontStyle(regular)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.From != nil {
+		err = w.section("from", func() error {
+			return writeAddress(w, cfg.Labels.From, cfg.From, addressTemplate)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// pickup orders aren't shipped, and their shipping address is the store's, so they get the location instead
+	if details, ok := cfg.pickupOrder(order); cfg.Pickup.Show && ok {
+		err = w.section("pickup", func() error {
+			writePickup(w, details, cfg)
+			return nil
+		})
+	} else {
+		if order.ShippingAddress == nil {
+			cfg.warn("Order has no shipping address", "order", order.Name)
+		}
+		err = w.section("ship to", func() error {
+			return writeAddress(w, cfg.Labels.ShipTo, order.ShippingAddress, addressTemplate)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// billing is usually the same as shipping, so only show it when it adds something
+	if cfg.Billing.Show && (cfg.Billing.AlwaysShow || !sameAddress(order.BillingAddress, order.ShippingAddress)) {
+		err = w.section("bill to", func() error {
+			return writeAddress(w, cfg.Labels.BillTo, order.BillingAddress, addressTemplate)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.NoteAttributes.Show {
+		err = w.section("delivery instructions", func() error {
+			writeNoteAttributes(w, order, cfg)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.Metafields.Keys) > 0 {
+		err = w.section("metafields", func() error {
+			writeMetafields(w, order, cfg)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Payment.Show {
+		err = w.section("payment", func() error {
+			writePayment(w, order, cfg)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Discounts.ShowOrderDiscounts {
+		err = w.section("order discounts", func() error {
+			writeOrderDiscounts(w, order, cfg)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var itemTemplate *template.Template
+	if cfg.Text.ItemTemplate != "" {
+		itemTemplate, err = template.New("item").Parse(cfg.Text.ItemTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to parse item-template: %w", err)
+		}
+	}
+
+	err = w.section("items", func() error {
+		// draft and fully refunded orders can have nothing in them
+		if len(order.LineItems) == 0 {
+			cfg.warn("Order has no line items", "order", order.Name)
+			w.changeFontStyle(regular)
+			w.writeLine(cfg.Labels.NoItems + "\n\n")
+		}
+
+		if cfg.Items.Summary && len(order.LineItems) > 0 {
+			w.changeFontStyle(bold)
+			w.writeLine(packSummary(order.LineItems, cfg.Labels) + "\n\n")
+		}
+
+		lineItems, err := sortLineItems(order.LineItems, cfg.Items.Sort, cfg.Items.Locations)
+		if err != nil {
+			return err
+		}
+		return writeItems(w, order, lineItems, cfg, itemTemplate)
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.Text.Returns != "" {
+		err = w.section("returns", func() error {
+			writeReturns(w, cfg)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.section("signature", func() error {
+		w.writeLine(cfg.Text.Salutation)
+		w.changeFontStyle(bold)
+		w.writeLine(cfg.Text.Signature)
+		return nil
+	})
+}
+
+// writeAddress writes a bold heading followed by the lines of an address, laid out by the
+// address-template if there is one. Nothing is written if the address is nil.
+func writeAddress(w slipWriter, heading string, a *goshopify.Address, t *template.Template) error {
+	if a == nil {
+		return nil
+	}
+
+	w.writeHeading(heading)
+	if t != nil {
+		return writeAddressTemplate(w, t, a)
+	}
+	w.writeLine(a.FirstName + " " + a.LastName)
+	w.writeLine(a.Address1)
+	if a.Address2 != "" {
+		w.writeLine(a.Address2)
+	}
+
+	citystate := strings.Builder{}
+	citystate.WriteString(a.City)
+	citystate.WriteString(" ")
+	citystate.WriteString(a.ProvinceCode)
+	citystate.WriteString(" ")
+	citystate.WriteString(a.Zip)
+	citystate.WriteString("\n")
+	w.writeLine(citystate.String())
+	w.writeLine(a.Country + "\n\n")
+	return nil
+}
+
+// writeAddressTemplate writes the address using the address-template from the config.
+// Blank lines are left out, so a line for a field like {{.Company}} disappears when it's empty.
+func writeAddressTemplate(w slipWriter, t *template.Template, a *goshopify.Address) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, a); err != nil {
+		return fmt.Errorf("failed to render address-template: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	w.writeLine(strings.Join(lines, "\n") + "\n\n")
+	return nil
+}
+
+// writeHeaderTemplate writes the header using the header-template from the config, in place of
+// the order name and date
+func writeHeaderTemplate(w slipWriter, t *template.Template, order goshopify.Order) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, order); err != nil {
+		return fmt.Errorf("failed to render header-template: %w", err)
+	}
+
+	w.changeFontStyle(regular)
+	w.writeLine(strings.TrimRight(buf.String(), "\n") + "\n\n")
+	return nil
+}
+
+// writeItemTemplate writes a line item using the item-template from the config.
+// Items are always followed by a blank line, like the default layout.
+func writeItemTemplate(w slipWriter, t *template.Template, lineItem goshopify.LineItem) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, lineItem); err != nil {
+		return fmt.Errorf("failed to render item-template: %w", err)
+	}
+
+	w.changeFontStyle(regular)
+	w.writeLine(strings.TrimRight(buf.String(), "\n") + "\n\n")
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/getsops/sops/v3"
+	"gopkg.in/yaml.v2"
+)
+
+// userError is implemented by sops errors that carry a longer explanation,
+// like the one listing why each key group failed
+type userError interface {
+	UserError() string
+}
+
+// isSopsEncrypted reports whether yaml data is a sops encrypted file,
+// which always has a top-level sops key holding its metadata
+func isSopsEncrypted(data []byte) bool {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	_, ok := doc["sops"]
+	return ok
+}
+
+// explainDecryptError wraps a sops decryption error for the named file (e.g. "secrets")
+// with a hint about the usual cause. The original error is kept so it can still be inspected.
+func explainDecryptError(what string, err error) error {
+	if errors.Is(err, errDecryptTimeout) {
+		return fmt.Errorf("could not decrypt %s: %w", what, err)
+	}
+	if errors.Is(err, sops.MetadataNotFound) {
+		return fmt.Errorf("could not decrypt %s: the file has no sops metadata, so it isn't encrypted; encrypt it with sops -e -i: %w", what, err)
+	}
+
+	detail := err.Error()
+	var ue userError
+	if errors.As(err, &ue) {
+		detail = ue.UserError()
+	}
+	detail = strings.ToLower(detail)
+
+	var hint string
+	switch {
+	case strings.Contains(detail, "failed to load age identities"),
+		strings.Contains(detail, "no identity matched any of the recipients"):
+		hint = "no age key found that matches the file; set SOPS_AGE_KEY_FILE or put your key in ~/.config/sops/age/keys.txt"
+	case strings.Contains(detail, "aws kms"):
+		hint = "AWS KMS refused to decrypt the data key; check your AWS credentials and the key's permissions"
+	case strings.Contains(detail, "gcp kms"):
+		hint = "GCP KMS refused to decrypt the data key; check your Google credentials and the key's permissions"
+	case strings.Contains(detail, "azure key vault"):
+		hint = "Azure Key Vault refused to decrypt the data key; check your Azure credentials and the key's permissions"
+	case strings.Contains(detail, "vault transit"):
+		hint = "Vault refused to decrypt the data key; check VAULT_ADDR and your Vault token"
+	case strings.Contains(detail, "pgp"):
+		hint = "no PGP key found that can decrypt the file; check that its secret key is in your keyring"
+	default:
+		return fmt.Errorf("failed to decrypt %s file: %w", what, err)
+	}
+
+	return fmt.Errorf("could not decrypt %s: %s: %w", what, hint, err)
+}
+
+// decryptTimeout is the --decrypt-timeout, the longest decrypting a file (with any retries) can take
+var decryptTimeout = 30 * time.Second
+
+// the number of times a decryption is tried again after the key service failed in a way that may not last
+const decryptRetries = 2
+
+// errDecryptTimeout is returned when the key service didn't answer within the --decrypt-timeout.
+// It says so, since a hang here is easy to take for a slow Shopify.
+var errDecryptTimeout = errors.New("the key service (KMS, Key Vault or Vault) didn't answer in time; this is the sops decryption, not Shopify, raise --decrypt-timeout if it's just slow")
+
+// decryptWithRetry runs a sops decryption, trying it again if it fails for a reason that may not last,
+// like a dropped connection to the KMS, and giving up after the --decrypt-timeout.
+// sops can't be cancelled, so a decryption that times out is just left behind.
+func decryptWithRetry(decrypt func() ([]byte, error)) ([]byte, error) {
+	ctx := context.Background()
+	if decryptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, decryptTimeout)
+		defer cancel()
+	}
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	for attempt := 0; ; attempt++ {
+		done := make(chan result, 1)
+		go func() {
+			data, err := decrypt()
+			done <- result{data, err}
+		}()
+
+		var r result
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (gave up after %s)", errDecryptTimeout, decryptTimeout)
+		case r = <-done:
+		}
+		if r.err == nil || attempt == decryptRetries || !transientDecryptError(r.err) {
+			return r.data, r.err
+		}
+
+		log.Info("Decrypting failed, trying again", "err", r.err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (gave up after %s)", errDecryptTimeout, decryptTimeout)
+		case <-time.After(time.Duration(attempt+1) * time.Second):
+		}
+	}
+}
+
+// transientDecryptErrors are parts of the errors the key services' clients give for failures that may not last
+var transientDecryptErrors = []string{
+	"timeout", "timed out", "connection reset", "connection refused", "broken pipe", "eof",
+	"temporarily unavailable", "service unavailable", "throttl", "too many requests", "internal error", "i/o timeout",
+}
+
+// transientDecryptError reports whether a sops error looks like the key service failing for the moment,
+// rather than a missing key or no permission, which trying again won't fix
+func transientDecryptError(err error) bool {
+	detail := err.Error()
+	var ue userError
+	if errors.As(err, &ue) {
+		detail = ue.UserError()
+	}
+	detail = strings.ToLower(detail)
+	for _, s := range transientDecryptErrors {
+		if strings.Contains(detail, s) {
+			return true
+		}
+	}
+	return false
+}
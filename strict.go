@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io"
+
+	"github.com/charmbracelet/log"
+)
+
+// warnings is the number of warnings logged so far, so --strict can fail the run if there were any
+var warnings int
+
+// warn logs a warning and counts it
+func warn(msg interface{}, keyvals ...interface{}) {
+	warnings++
+	log.Warn(msg, keyvals...)
+}
+
+// warningCounter counts what the slip package logs, which is only ever warnings
+type warningCounter struct {
+	w io.Writer
+}
+
+func (c warningCounter) Write(p []byte) (int, error) {
+	warnings++
+	return c.w.Write(p)
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rahji/packingslipper/slip"
+	"golang.org/x/term"
+)
+
+// TUICmd takes the same flags as render, so the slips it renders come out like a render with them would
+type TUICmd struct {
+	RenderCmd `kong:"embed"`
+}
+
+// the size the screen is drawn for until the terminal says
+const (
+	defaultTermWidth  = 80
+	defaultTermHeight = 24
+)
+
+// selectedStyle highlights the order the Enter key renders
+var selectedStyle = lipgloss.NewStyle().Reverse(true)
+
+// pickedOrder is an order in the list, with its offset for the list's first column
+type pickedOrder struct {
+	order  goshopify.Order
+	offset int
+}
+
+// orderPicker is the tui's list of orders, as a Bubble Tea model
+type orderPicker struct {
+	orders   []pickedOrder
+	selected int
+	top      int // the first order that fits on the screen
+	width    int
+	height   int
+	status   string
+	render   func(pickedOrder) string
+}
+
+// renderedMsg is how a render went, for the status line
+type renderedMsg string
+
+// Run lists the recent orders (--count of them, from --offset) and renders the one picked with Enter,
+// opening it in the default viewer, until q is pressed
+func (t *TUICmd) Run(cli *CLIFlags) error {
+	if t.combine() || t.ListOrders || t.Watch || t.FulfillmentOrderID != 0 || t.Resume || t.NoResume {
+		return fmt.Errorf("tui renders one order at a time, so it can't be used with --combine, --customer-email, --query, --tag, --orders-file, --list-orders, --watch, --fulfillment-order-id or --resume")
+	}
+	// the slips are opened in the viewer, so they have to be PDF files
+	if t.Format != "pdf" || t.OutFilename == "-" || t.Zip != "" {
+		return fmt.Errorf("tui only renders PDF files, so it can't be used with --format text, --outfile - or --zip")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("tui needs a terminal")
+	}
+
+	orders, err := t.pickableOrders(cli)
+	if err != nil {
+		return err
+	}
+	picker := &orderPicker{
+		orders: orders,
+		width:  defaultTermWidth,
+		height: defaultTermHeight,
+		render: func(picked pickedOrder) string { return t.render(cli, picked) },
+	}
+	_, err = tea.NewProgram(picker, tea.WithAltScreen()).Run()
+	return err
+}
+
+// pickableOrders fetches the orders the list shows, leaving out test orders with --skip-test or --strict
+func (t *TUICmd) pickableOrders(cli *CLIFlags) ([]pickedOrder, error) {
+	// a copy, so the offsets stay as they were given for the renders
+	r := t.RenderCmd
+	if err := r.useOrderFlags(); err != nil {
+		return nil, err
+	}
+	secrets, err := loadSecrets(cli.SecretsFilename)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClient(*secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	count := r.Count
+	if count <= 0 {
+		count = defaultListCount
+	}
+	orders, err := r.fetchOrders(ctx, client, r.OrderOffset+count)
+	if err != nil {
+		return nil, err
+	}
+	if r.OrderOffset >= len(orders) {
+		return nil, r.noOrders()
+	}
+
+	var picked []pickedOrder
+	for i, o := range orders[r.OrderOffset:min(r.OrderOffset+count, len(orders))] {
+		if (r.SkipTest || r.Strict) && slip.IsTestOrder(o) {
+			continue
+		}
+		picked = append(picked, pickedOrder{order: o, offset: r.OrderOffset + i})
+	}
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("not listing test orders with --skip-test or --strict")
+	}
+	return picked, nil
+}
+
+// render renders the picked order the way render with the same flags would, and returns how it
+// went for the status line. It's the order already fetched for the list that's rendered, so an
+// order placed since then can't shift the offsets and put a different one on the slip.
+func (t *TUICmd) render(cli *CLIFlags, picked pickedOrder) string {
+	// a fresh copy each time, since a render fills in some of its flags
+	r := t.RenderCmd
+	r.picked = &picked.order
+	r.Preview = true
+	if err := r.run(cli); err != nil {
+		fmt.Printf("Failed: %v\n", err)
+		return fmt.Sprintf("Failed to render %s: %v", picked.order.Name, err)
+	}
+	return fmt.Sprintf("Rendered %s to %s", picked.order.Name, r.outFilenames()[0])
+}
+
+// Init starts the picker without a command to run
+func (p *orderPicker) Init() tea.Cmd {
+	return nil
+}
+
+// Update moves the selection with the keys, and renders the selected order with Enter outside
+// of the picker's screen, so the render's log is shown the way it is without the tui
+func (p *orderPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+	case renderedMsg:
+		p.status = string(msg)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return p, tea.Quit
+		case "enter":
+			run := &renderRun{picked: p.orders[p.selected], render: p.render}
+			return p, tea.Exec(run, func(error) tea.Msg { return renderedMsg(run.status) })
+		case "k", "up":
+			p.selected = max(p.selected-1, 0)
+		case "j", "down":
+			p.selected = min(p.selected+1, len(p.orders)-1)
+		case "g", "home":
+			p.selected = 0
+		case "G", "end":
+			p.selected = len(p.orders) - 1
+		}
+	}
+	return p, nil
+}
+
+// View draws the list, with a line per order and the selected one highlighted,
+// and under it the details of the selected order
+func (p *orderPicker) View() string {
+	// the list gets up to half the screen, scrolling to keep the selected order on it
+	rows := max(min(len(p.orders), p.height/2-2), 1)
+	if p.selected < p.top {
+		p.top = p.selected
+	}
+	if p.selected >= p.top+rows {
+		p.top = p.selected - rows + 1
+	}
+
+	lines := []string{"Up/Down to pick an order, Enter to render it, q to quit", ""}
+	for i := p.top; i < min(p.top+rows, len(p.orders)); i++ {
+		line := truncate(orderRow(p.orders[i]), p.width)
+		if i == p.selected {
+			line = selectedStyle.Width(p.width).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "")
+	for _, line := range orderDetails(p.orders[p.selected].order) {
+		lines = append(lines, truncate(line, p.width))
+	}
+
+	// the status goes on the last line, with the details cut short to leave room for it
+	if len(lines) > p.height-2 {
+		lines = lines[:max(p.height-2, 0)]
+	}
+	for len(lines) < p.height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, truncate(p.status, p.width))
+	return strings.Join(lines, "\n")
+}
+
+// renderRun renders an order with the terminal handed back from the picker, then waits for Enter
+// so its log can be read before the list is drawn over it
+type renderRun struct {
+	picked pickedOrder
+	render func(pickedOrder) string
+	status string
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+// SetStdin, SetStdout and SetStderr are how tea.Exec hands the terminal over
+func (r *renderRun) SetStdin(stdin io.Reader)   { r.stdin = stdin }
+func (r *renderRun) SetStdout(stdout io.Writer) { r.stdout = stdout }
+func (r *renderRun) SetStderr(io.Writer)        {}
+
+// Run renders the order, with the terminal back in its usual mode
+func (r *renderRun) Run() error {
+	fmt.Fprintf(r.stdout, "Rendering %s\n", r.picked.order.Name)
+	r.status = r.render(r.picked)
+	fmt.Fprint(r.stdout, "\nPress Enter to go back to the list")
+	_, err := bufio.NewReader(r.stdin).ReadString('\n')
+	return err
+}
+
+// orderRow is the order's line in the list, with the same columns as --list-orders
+func orderRow(picked pickedOrder) string {
+	o := picked.order
+	date := ""
+	if o.CreatedAt != nil {
+		date = o.CreatedAt.Format("Jan 2, 2006")
+	}
+	return fmt.Sprintf("%4d  %-10s %-12s %s", picked.offset, o.Name, date, customerName(o))
+}
+
+// orderDetails are the lines about the selected order under the list: its statuses,
+// where it's going, its note and its items
+func orderDetails(o goshopify.Order) []string {
+	fulfillment := o.FulfillmentStatus
+	if fulfillment == "" {
+		fulfillment = "unfulfilled"
+	}
+	lines := []string{fmt.Sprintf("%s  %s, %s", o.Name, o.FinancialStatus, fulfillment)}
+	if a := o.ShippingAddress; a != nil {
+		place := strings.Join(nonEmpty(a.City, a.ProvinceCode, a.CountryCode), ", ")
+		lines = append(lines, fmt.Sprintf("Ship to: %s %s, %s", a.FirstName, a.LastName, place))
+	}
+	if o.Note != "" {
+		lines = append(lines, "Note: "+strings.Join(strings.Fields(o.Note), " "))
+	}
+	lines = append(lines, "")
+	for _, item := range o.LineItems {
+		lines = append(lines, fmt.Sprintf("%3d x %s", item.Quantity, item.Name))
+	}
+	return lines
+}
+
+// nonEmpty returns the strings that aren't empty
+func nonEmpty(values ...string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != "" {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// truncate cuts the line to the width of the terminal, so a long one doesn't wrap and push the rest down
+func truncate(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	return string(runes[:width])
+}
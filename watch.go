@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+	"github.com/rahji/packingslipper/slip"
+)
+
+// how long to wait for an editor to finish writing a file before rendering again
+const watchDelay = 200 * time.Millisecond
+
+// watch renders the orders, then renders them again each time one of the config files,
+// the logo or the stamp changes, until it's interrupted. The orders are only fetched once.
+func (r *RenderCmd) watch(cli *CLIFlags, orders []goshopify.Order, cfg slip.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := r.writeSlips(orders, cfg); err != nil {
+		return err
+	}
+	log.Info("Rendered", "file", r.OutFilename)
+	r.runPostHooks(orders)
+	if r.Preview && r.OutFilename != "-" {
+		if err := openFile(r.outFilenames()[0]); err != nil {
+			return err
+		}
+	}
+
+	// editors often replace a file instead of writing to it, so watch the directories
+	// and pick out the files we care about
+	watched := map[string]bool{}
+	addWatch := func(fn string) error {
+		abs, err := filepath.Abs(fn)
+		if err != nil {
+			return err
+		}
+		if watched[abs] {
+			return nil
+		}
+		watched[abs] = true
+		if err := watcher.Add(filepath.Dir(abs)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", fn, err)
+		}
+		return nil
+	}
+	for _, fn := range cli.ConfigFilenames {
+		if err := addWatch(fn); err != nil {
+			return err
+		}
+	}
+	// a compact slip can go without a logo
+	if cfg.Logo.Filename != "" {
+		if err := addWatch(cfg.Logo.Filename); err != nil {
+			return err
+		}
+	}
+	if cfg.Stamp.Filename != "" {
+		if err := addWatch(cfg.Stamp.Filename); err != nil {
+			return err
+		}
+	}
+
+	log.Info("Watching for changes, press Ctrl-C to stop")
+
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			return err
+		case event := <-watcher.Events:
+			abs, _ := filepath.Abs(event.Name)
+			if watched[abs] && event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				timer = time.After(watchDelay)
+			}
+		case <-timer:
+			timer = nil
+			newCfg, err := r.reloadConfig(cli)
+			if err != nil {
+				log.Error("Not rendering", "err", err)
+				metrics.fail("config")
+				continue
+			}
+			newCfg.From = cfg.From
+			newCfg.PDFMetadata.Shop = cfg.PDFMetadata.Shop
+			newCfg.Risk.Recommendations = cfg.Risk.Recommendations
+			// the logo and stamp can be switched to files that aren't watched yet
+			if newCfg.Logo.Filename != "" {
+				if err := addWatch(newCfg.Logo.Filename); err != nil {
+					log.Error("Not watching logo", "err", err)
+				}
+			}
+			if newCfg.Stamp.Filename != "" {
+				if err := addWatch(newCfg.Stamp.Filename); err != nil {
+					log.Error("Not watching stamp", "err", err)
+				}
+			}
+			if err := r.writeSlips(orders, *newCfg); err != nil {
+				log.Error("Failed to render", "err", err)
+				metrics.fail("render")
+				continue
+			}
+			log.Info("Rendered", "file", r.OutFilename)
+			r.runPostHooks(orders)
+		}
+	}
+}
+
+// reloadConfig reads the config files again and applies the flags to them, the same way Run does
+func (r *RenderCmd) reloadConfig(cli *CLIFlags) (*slip.Config, error) {
+	cfg, err := loadConfigFiles(cli.ConfigFilenames)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.applyPreset(cfg); err != nil {
+		return nil, err
+	}
+	r.applyFlags(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	goshopify "github.com/bold-commerce/go-shopify/v4"
+	"github.com/rahji/packingslipper/slip"
+)
+
+// the name of the --manifest entry in the --zip
+const zipManifestName = "manifest.csv"
+
+// writeZip writes a PDF for each order to the --zip archive in place of the --outfile, named with the
+// --outfile template if it has one or like 1001.pdf, and a manifest.csv with --manifest. Each PDF goes
+// straight into the archive as it's rendered, so only one order's slip is in memory at a time.
+func (r *RenderCmd) writeZip(orders []goshopify.Order, cfg slip.Config) error {
+	if r.Zip == "-" {
+		return r.writeZipTo(os.Stdout, orders, cfg)
+	}
+	f, err := os.Create(r.Zip)
+	if err != nil {
+		return err
+	}
+	if err := r.writeZipTo(f, orders, cfg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeZipTo writes the --zip archive to w
+func (r *RenderCmd) writeZipTo(w io.Writer, orders []goshopify.Order, cfg slip.Config) error {
+	zw := zip.NewWriter(w)
+	modified := time.Now()
+	used := map[string]bool{}
+
+	err := eachOrderSlip(orders, cfg, func(order goshopify.Order, cfg slip.Config) error {
+		name, err := outfileName("", r.zipEntries, order)
+		if err != nil {
+			return err
+		}
+		entry, err := zw.CreateHeader(&zip.FileHeader{Name: uniqueEntryName(name, used), Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		return slip.RenderSlips([]goshopify.Order{order}, cfg, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.Manifest {
+		entry, err := zw.CreateHeader(&zip.FileHeader{Name: uniqueEntryName(zipManifestName, used), Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if err := writeManifest(entry, orders, r.CSVColumns); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// uniqueEntryName returns the name, or the name with a number before the extension (like 1001-2.pdf)
+// if it's already used, since a template like {{.Date}}.pdf can give orders the same name
+func uniqueEntryName(name string, used map[string]bool) string {
+	unique := name
+	ext := filepath.Ext(name)
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+	}
+	used[unique] = true
+	return unique
+}